@@ -0,0 +1,140 @@
+// Package analyzer provides fillstruct as an analysis.Analyzer, e.g., for go vet -vettool
+// with singlechecker or for gopls.
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"github.com/nametake/fillstruct"
+	"github.com/nametake/fillstruct/internal/deps"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/packages"
+)
+
+// Analyzer reports keyed struct literals with missing fields, with a suggested fix adding them
+var Analyzer = &analysis.Analyzer{
+	Name: "fillstruct",
+	Doc:  "report struct literals with missing fields\n\nEach diagnostic has a suggested fix that adds the missing fields with their zero values.",
+	Run:  run,
+}
+
+// typeSpecs is the value of the -type flag
+var typeSpecs string
+
+func init() {
+	Analyzer.Flags.StringVar(&typeSpecs, "type", "", "comma-separated target types (importpath.TypeName, importpath.* or TypeName for types in the analyzed package); all literals are checked when empty")
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	option := &fillstruct.Option{}
+	for _, spec := range strings.Split(typeSpecs, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		i := strings.LastIndex(spec, ".")
+		if i < 0 {
+			option.TargetTypeNames = append(option.TargetTypeNames, spec)
+			continue
+		}
+		// Types of packages the analyzed package does not depend on have no literals in it
+		option.TargetTypes = append(option.TargetTypes, lookupStructTypes(pass.Pkg, spec[:i], spec[i+1:])...)
+	}
+	if strings.TrimSpace(typeSpecs) != "" && len(option.TargetTypes) == 0 && len(option.TargetTypeNames) == 0 {
+		// None of the target types can be used in the package
+		return nil, nil
+	}
+
+	pkg := &packages.Package{
+		Fset:      pass.Fset,
+		Syntax:    pass.Files,
+		Types:     pass.Pkg,
+		TypesInfo: pass.TypesInfo,
+		PkgPath:   pass.Pkg.Path(),
+		Name:      pass.Pkg.Name(),
+	}
+	for _, file := range pass.Files {
+		if err := checkFile(pass, pkg, file, option); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// checkFile reports the incomplete literals of the file. The file is filled once; the
+// suggested fix of each literal inserts its missing fields and adds the imports they need.
+func checkFile(pass *analysis.Pass, pkg *packages.Package, file *ast.File, option *fillstruct.Option) error {
+	result, err := fillstruct.FormatContext(context.Background(), pkg, file, option)
+	if err != nil {
+		return err
+	}
+
+	tokFile := pass.Fset.File(file.Pos())
+	textEdits := func(edits []fillstruct.TextEdit) []analysis.TextEdit {
+		var converted []analysis.TextEdit
+		for _, edit := range edits {
+			converted = append(converted, analysis.TextEdit{
+				Pos:     tokFile.Pos(edit.Start),
+				End:     tokFile.Pos(edit.End),
+				NewText: []byte(edit.NewText),
+			})
+		}
+		return converted
+	}
+
+	for _, lit := range result.Literals {
+		if len(lit.Missing) == 0 {
+			continue
+		}
+		diagnostic := analysis.Diagnostic{
+			Pos:     tokFile.Pos(lit.Position.Offset),
+			Message: fmt.Sprintf("%s literal is missing fields: %s", typeName(lit.Type, pass.Pkg), strings.Join(lit.Missing, ", ")),
+		}
+		if len(lit.Edits) > 0 {
+			diagnostic.SuggestedFixes = []analysis.SuggestedFix{{
+				Message:   "Fill missing fields",
+				TextEdits: append(textEdits(lit.Edits), textEdits(result.ImportEdits)...),
+			}}
+		}
+		pass.Report(diagnostic)
+	}
+	return nil
+}
+
+// lookupStructTypes returns the struct type with the name, or all struct types when the name
+// is "*", of the package with the import path among p and its dependencies
+func lookupStructTypes(p *types.Package, importPath, name string) []*types.Named {
+	dep := deps.Find(p, func(p *types.Package) bool {
+		return p.Path() == importPath
+	})
+	if dep == nil {
+		return nil
+	}
+	names := []string{name}
+	if name == "*" {
+		names = dep.Scope().Names()
+	}
+	var named []*types.Named
+	for _, name := range names {
+		obj, ok := dep.Scope().Lookup(name).(*types.TypeName)
+		if !ok || obj.IsAlias() {
+			continue
+		}
+		if t, ok := obj.Type().(*types.Named); ok {
+			if _, ok := t.Underlying().(*types.Struct); ok {
+				named = append(named, t)
+			}
+		}
+	}
+	return named
+}
+
+// typeName returns the type reported by fillstruct relative to the analyzed package,
+// e.g., "User" instead of "example.com/models.User" for its own types
+func typeName(typ string, p *types.Package) string {
+	return strings.TrimPrefix(typ, p.Path()+".")
+}
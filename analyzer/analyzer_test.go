@@ -0,0 +1,59 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), Analyzer, "a")
+}
+
+func TestAnalyzer_Type(t *testing.T) {
+	if err := Analyzer.Flags.Set("type", "a.Person"); err != nil {
+		t.Fatalf("failed to set -type: %v", err)
+	}
+	t.Cleanup(func() {
+		Analyzer.Flags.Set("type", "")
+	})
+
+	// Team literals are not reported since only a.Person is targeted
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "b")
+}
+
+// TestAnalyzer_FixScope checks that the fix of a literal only edits the literal and the
+// import declaration, leaving the unformatted declaration of the file as written
+func TestAnalyzer_FixScope(t *testing.T) {
+	results := analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), Analyzer, "c")
+	for _, result := range results {
+		file := result.Pass.Files[slices.IndexFunc(result.Pass.Files, func(f *ast.File) bool {
+			return result.Pass.Fset.File(f.Pos()).Name() == filepath.Join(analysistest.TestData(), "src", "c", "c.go")
+		})]
+		imports := file.Decls[0].(*ast.GenDecl)
+		literals := make(map[token.Pos]*ast.CompositeLit)
+		ast.Inspect(file, func(n ast.Node) bool {
+			if lit, ok := n.(*ast.CompositeLit); ok {
+				literals[lit.Pos()] = lit
+			}
+			return true
+		})
+
+		for _, diagnostic := range result.Diagnostics {
+			lit := literals[diagnostic.Pos]
+			for _, fix := range diagnostic.SuggestedFixes {
+				for _, edit := range fix.TextEdits {
+					inLiteral := edit.Pos >= lit.Lbrace && edit.End <= lit.Rbrace
+					inImports := edit.Pos >= imports.Pos() && edit.End <= imports.End()
+					if !inLiteral && !inImports {
+						t.Errorf("fix of the literal at %v edits %v", result.Pass.Fset.Position(lit.Pos()), result.Pass.Fset.Position(edit.Pos))
+					}
+				}
+			}
+		}
+	}
+}
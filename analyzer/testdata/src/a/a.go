@@ -0,0 +1,32 @@
+package a
+
+type Person struct {
+	Name string
+	Age  int
+	Tags []string
+}
+
+type Team struct {
+	Name    string
+	Members []Person
+}
+
+func people() []Person {
+	_ = Person{Name: "complete", Age: 30, Tags: nil}
+	_ = Team{ // want `Team literal is missing fields: Members`
+		Name: "team",
+	}
+	return []Person{
+		{Name: "alice"}, // want `Person literal is missing fields: Age, Tags`
+
+		{Age: 2, Tags: nil}, // want `Person literal is missing fields: Name`
+	}
+}
+
+func events() []Event {
+	return []Event{
+		{Name: "start"}, // want `Event literal is missing fields: When`
+
+		{Name: "stop"}, // want `Event literal is missing fields: When`
+	}
+}
@@ -0,0 +1,8 @@
+package a
+
+import "time"
+
+type Event struct {
+	Name string
+	When time.Time
+}
@@ -0,0 +1,7 @@
+package b
+
+import "a"
+
+var _ = a.Person{Name: "bob"} // want `a.Person literal is missing fields: Age, Tags`
+
+var _ = a.Team{Members: nil}
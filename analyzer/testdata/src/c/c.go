@@ -0,0 +1,14 @@
+package c
+
+import "fmt"
+
+var  unrelated   =  fmt.Sprint(1)
+
+func events() []Event {
+	return []Event{
+		{Name: "start"}, // want `Event literal is missing fields: When`
+		{ // want `Event literal is missing fields: When`
+			Name: "stop",
+		},
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// configFileName is the name of the configuration file
+const configFileName = ".fillstruct.yaml"
+
+// configFileNames are the names of the configuration files searched for, in order of preference
+var configFileNames = []string{configFileName, ".fillstruct.json"}
+
+// config is the content of a configuration file. It covers only the flags below, under
+// their names with underscores (--type as types, --default as defaults); the other flags
+// can only be given on the command line.
+type config struct {
+	Types              []string          `yaml:"types" json:"types"`
+	Defaults           map[string]string `yaml:"defaults" json:"defaults"`
+	Tags               string            `yaml:"tags" json:"tags"`
+	IncludeGenerated   bool              `yaml:"include_generated" json:"include_generated"`
+	UnknownPlaceholder string            `yaml:"unknown_placeholder" json:"unknown_placeholder"`
+	ExcludeFieldRegexp string            `yaml:"exclude_field_regexp" json:"exclude_field_regexp"`
+	FollowSymlinks     bool              `yaml:"follow_symlinks" json:"follow_symlinks"`
+	NoFormat           bool              `yaml:"no_format" json:"no_format"`
+}
+
+// configComments documents each key of the scaffolded configuration file
+var configComments = map[string]string{
+	"types":                "Target types (importpath.TypeName, or TypeName for types in the processed packages)",
+	"defaults":             "Custom default values (TypeSpec: ConstantName)",
+	"tags":                 "Comma-separated build tags to consider when loading packages",
+	"include_generated":    "Also fill generated files outside of //fillstruct:begin and //fillstruct:end regions",
+	"unknown_placeholder":  "Expression used instead of nil for fields of unsupported types",
+	"exclude_field_regexp": "Skip fields whose name matches the regular expression (e.g., '^XXX_')",
+	"follow_symlinks":      "Write symlinked files through to their target (skip them when false)",
+	"no_format":            "Skip gofmt on the output",
+}
+
+// defaultConfig returns the configuration matching the defaults of the command line flags
+func defaultConfig() *config {
+	return &config{
+		Types:          []string{},
+		Defaults:       map[string]string{},
+		FollowSymlinks: true,
+	}
+}
+
+// marshalConfig encodes the configuration with a comment above each key
+func marshalConfig(c *config) ([]byte, error) {
+	var node yaml.Node
+	if err := node.Encode(c); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	node.HeadComment = "fillstruct configuration"
+	for i := 0; i < len(node.Content); i += 2 {
+		key := node.Content[i]
+		key.HeadComment = configComments[key.Value]
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// initConfig writes a configuration file with the default values to dir.
+// It refuses to overwrite an existing file.
+func initConfig(dir string) (string, error) {
+	content, err := marshalConfig(defaultConfig())
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, configFileName)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if errors.Is(err, fs.ErrExist) {
+		return "", fmt.Errorf("%s already exists", path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// findConfig searches dir and then its parents, up to the module root (the first
+// directory containing go.mod), for a configuration file. It returns "" when none exists.
+func findConfig(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", dir, err)
+	}
+	for {
+		for _, name := range configFileNames {
+			path := filepath.Join(dir, name)
+			if _, err := os.Stat(path); err == nil {
+				return path, nil
+			} else if !errors.Is(err, fs.ErrNotExist) {
+				return "", fmt.Errorf("failed to stat %s: %w", path, err)
+			}
+		}
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return "", nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// loadConfig reads a YAML or JSON configuration file. Keys that are not set keep
+// their default values, and unknown keys are rejected.
+func loadConfig(path string) (*config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	c := defaultConfig()
+	if filepath.Ext(path) == ".json" {
+		dec := json.NewDecoder(bytes.NewReader(content))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(c)
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(content))
+		dec.KnownFields(true)
+		err = dec.Decode(c)
+		if errors.Is(err, io.EOF) {
+			// An empty file
+			err = nil
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// applyConfig sets the flags that were not given on the command line from the
+// configuration. Types are merged with the -type flags, and -default flags take
+// precedence over defaults of the same type.
+func applyConfig(flags *flag.FlagSet, c *config, typeFlags, defaultFlags *arrayFlags) error {
+	set := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	values := map[string]string{
+		"tags":                 c.Tags,
+		"include-generated":    strconv.FormatBool(c.IncludeGenerated),
+		"unknown-placeholder":  c.UnknownPlaceholder,
+		"exclude-field-regexp": c.ExcludeFieldRegexp,
+		"follow-symlinks":      strconv.FormatBool(c.FollowSymlinks),
+		"no-format":            strconv.FormatBool(c.NoFormat),
+	}
+	for name, value := range values {
+		if set[name] {
+			continue
+		}
+		if err := flags.Set(name, value); err != nil {
+			return fmt.Errorf("invalid %s in config: %w", name, err)
+		}
+	}
+
+	for _, typ := range c.Types {
+		if !slices.Contains(*typeFlags, typ) {
+			*typeFlags = append(*typeFlags, typ)
+		}
+	}
+
+	// Later specifications win in parseDefaultValues, so the flags go last
+	typeSpecs := make([]string, 0, len(c.Defaults))
+	for typeSpec := range c.Defaults {
+		typeSpecs = append(typeSpecs, typeSpec)
+	}
+	sort.Strings(typeSpecs)
+	defaults := make(arrayFlags, 0, len(typeSpecs)+len(*defaultFlags))
+	for _, typeSpec := range typeSpecs {
+		defaults = append(defaults, typeSpec+"="+c.Defaults[typeSpec])
+	}
+	*defaultFlags = append(defaults, *defaultFlags...)
+	return nil
+}
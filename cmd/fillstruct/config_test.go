@@ -0,0 +1,208 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.yaml.in/yaml/v3"
+)
+
+func TestInitConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	path, err := initConfig(dir)
+	if err != nil {
+		t.Fatalf("initConfig returned unexpected error: %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+
+	// The scaffolded file parses back into the defaults
+	var got config
+	if err := yaml.Unmarshal(content, &got); err != nil {
+		t.Fatalf("failed to parse scaffolded config: %v\n%s", err, content)
+	}
+	if diff := cmp.Diff(defaultConfig(), &got); diff != "" {
+		t.Errorf("scaffolded config mismatch (-want +got):\n%s", diff)
+	}
+
+	// An existing file is not overwritten
+	if err := os.WriteFile(path, []byte("types: [Custom]\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := initConfig(dir); err == nil {
+		t.Errorf("initConfig overwrote an existing config")
+	}
+	content, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+	if string(content) != "types: [Custom]\n" {
+		t.Errorf("existing config was modified: %q", content)
+	}
+}
+
+func TestFindConfig(t *testing.T) {
+	root := t.TempDir()
+	module := filepath.Join(root, "module")
+	sub := filepath.Join(module, "internal", "models")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatalf("failed to create directories: %v", err)
+	}
+	writeFile := func(path, content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+	writeFile(filepath.Join(module, "go.mod"), "module example.com/module\n")
+	// Above the module root, so it is never found
+	writeFile(filepath.Join(root, configFileName), "types: [Outside]\n")
+
+	find := func(dir string) string {
+		t.Helper()
+		path, err := findConfig(dir)
+		if err != nil {
+			t.Fatalf("findConfig returned unexpected error: %v", err)
+		}
+		return path
+	}
+
+	if got := find(sub); got != "" {
+		t.Errorf("findConfig found %s outside of the module", got)
+	}
+
+	jsonPath := filepath.Join(module, ".fillstruct.json")
+	writeFile(jsonPath, `{"types": ["User"]}`)
+	if got := find(sub); got != jsonPath {
+		t.Errorf("findConfig = %q, want %q", got, jsonPath)
+	}
+
+	// YAML is preferred in the same directory, and nearer directories first
+	yamlPath := filepath.Join(module, configFileName)
+	writeFile(yamlPath, "types: [User]\n")
+	if got := find(sub); got != yamlPath {
+		t.Errorf("findConfig = %q, want %q", got, yamlPath)
+	}
+	nearPath := filepath.Join(sub, configFileName)
+	writeFile(nearPath, "types: [User]\n")
+	if got := find(sub); got != nearPath {
+		t.Errorf("findConfig = %q, want %q", got, nearPath)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    *config
+		wantErr bool
+	}{
+		{
+			name:    "yaml keeps defaults for unset keys",
+			file:    configFileName,
+			content: "types:\n  - example.com/models.User\ndefaults:\n  int: \"1\"\n",
+			want: &config{
+				Types:          []string{"example.com/models.User"},
+				Defaults:       map[string]string{"int": "1"},
+				FollowSymlinks: true,
+			},
+		},
+		{
+			name:    "json",
+			file:    ".fillstruct.json",
+			content: `{"types": ["User"], "follow_symlinks": false}`,
+			want: &config{
+				Types:    []string{"User"},
+				Defaults: map[string]string{},
+			},
+		},
+		{
+			name:    "empty yaml",
+			file:    configFileName,
+			content: "",
+			want:    defaultConfig(),
+		},
+		{
+			name:    "unknown key",
+			file:    configFileName,
+			content: "type: [User]\n",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(dir, test.file)
+			if err := os.WriteFile(path, []byte(test.content), 0644); err != nil {
+				t.Fatalf("failed to write config: %v", err)
+			}
+			got, err := loadConfig(path)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("loadConfig returned no error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadConfig returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("config mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestApplyConfig(t *testing.T) {
+	flags := flag.NewFlagSet("fillstruct", flag.ContinueOnError)
+	var typeFlags, defaultFlags arrayFlags
+	flags.Var(&typeFlags, "type", "")
+	flags.Var(&defaultFlags, "default", "")
+	tags := flags.String("tags", "", "")
+	includeGenerated := flags.Bool("include-generated", false, "")
+	unknownPlaceholder := flags.String("unknown-placeholder", "", "")
+	flags.String("exclude-field-regexp", "", "")
+	followSymlinks := flags.Bool("follow-symlinks", true, "")
+	flags.Bool("no-format", false, "")
+	if err := flags.Parse([]string{"-type", "User", "-default", "int=8080", "-tags", "e2e"}); err != nil {
+		t.Fatalf("failed to parse flags: %v", err)
+	}
+
+	c := &config{
+		Types:              []string{"Order", "User"},
+		Defaults:           map[string]string{"int": "1", "string": `"none"`},
+		Tags:               "fixtures",
+		IncludeGenerated:   true,
+		UnknownPlaceholder: "TODO",
+		FollowSymlinks:     false,
+	}
+	if err := applyConfig(flags, c, &typeFlags, &defaultFlags); err != nil {
+		t.Fatalf("applyConfig returned unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(arrayFlags{"User", "Order"}, typeFlags); diff != "" {
+		t.Errorf("types mismatch (-want +got):\n%s", diff)
+	}
+	defaults, err := parseDefaultValues(defaultFlags)
+	if err != nil {
+		t.Fatalf("parseDefaultValues returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(map[string]string{"int": "8080", "string": `"none"`}, defaults); diff != "" {
+		t.Errorf("defaults mismatch (-want +got):\n%s", diff)
+	}
+	if *tags != "e2e" {
+		t.Errorf("tags = %q, want the flag value %q", *tags, "e2e")
+	}
+	if !*includeGenerated || *unknownPlaceholder != "TODO" || *followSymlinks {
+		t.Errorf("settings not taken from config: include-generated = %v, unknown-placeholder = %q, follow-symlinks = %v",
+			*includeGenerated, *unknownPlaceholder, *followSymlinks)
+	}
+}
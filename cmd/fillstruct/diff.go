@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nametake/fillstruct/internal/diff"
+)
+
+// unifiedDiff returns a unified diff between a and b with the given number of context
+// lines, or an empty string if they are equal
+func unifiedDiff(oldName, newName string, a, b []byte, context int) string {
+	ops := diff.Lines(diff.SplitLines(string(a)), diff.SplitLines(string(b)))
+
+	// Collect the indices of changed operations
+	var changes []int
+	for i, op := range ops {
+		if op.Kind != diff.Equal {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", oldName, newName)
+
+	for i := 0; i < len(changes); {
+		// Group changes whose context overlaps into a single hunk
+		j := i
+		for j+1 < len(changes) && changes[j+1]-changes[j] <= 2*context+1 {
+			j++
+		}
+		start := max(changes[i]-context, 0)
+		end := min(changes[j]+context+1, len(ops))
+		writeHunk(&sb, ops, start, end)
+		i = j + 1
+	}
+	return sb.String()
+}
+
+// writeHunk writes the operations in ops[start:end] as a single hunk
+func writeHunk(sb *strings.Builder, ops []diff.Op, start, end int) {
+	// Line numbers of the hunk start in both files
+	oldLine, newLine := 1, 1
+	for _, op := range ops[:start] {
+		if op.Kind != diff.Insert {
+			oldLine++
+		}
+		if op.Kind != diff.Delete {
+			newLine++
+		}
+	}
+
+	oldCount, newCount := 0, 0
+	var body strings.Builder
+	for _, op := range ops[start:end] {
+		switch op.Kind {
+		case diff.Equal:
+			oldCount++
+			newCount++
+			body.WriteString(" " + op.Line)
+		case diff.Delete:
+			oldCount++
+			body.WriteString("-" + op.Line)
+		case diff.Insert:
+			newCount++
+			body.WriteString("+" + op.Line)
+		}
+		if !strings.HasSuffix(op.Line, "\n") {
+			body.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+
+	// Empty ranges refer to the line before the hunk
+	if oldCount == 0 {
+		oldLine--
+	}
+	if newCount == 0 {
+		newLine--
+	}
+	fmt.Fprintf(sb, "@@ -%d,%d +%d,%d @@\n", oldLine, oldCount, newLine, newCount)
+	sb.WriteString(body.String())
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		a       string
+		b       string
+		context int
+		want    string
+	}{
+		{
+			name: "equal inputs produce no diff",
+			a:    "a\nb\n",
+			b:    "a\nb\n",
+			want: "",
+		},
+		{
+			name:    "inserted line is shown with context",
+			a:       "a\nb\nc\nd\n",
+			b:       "a\nb\nx\nc\nd\n",
+			context: 1,
+			want:    "--- old\n+++ new\n@@ -2,2 +2,3 @@\n b\n+x\n c\n",
+		},
+		{
+			name:    "distant changes are split into hunks",
+			a:       "1\n2\n3\n4\n5\n6\n7\n8\n",
+			b:       "0\n2\n3\n4\n5\n6\n7\n9\n",
+			context: 1,
+			want:    "--- old\n+++ new\n@@ -1,2 +1,2 @@\n-1\n+0\n 2\n@@ -7,2 +7,2 @@\n 7\n-8\n+9\n",
+		},
+		{
+			name:    "close changes share a hunk",
+			a:       "1\n2\n3\n4\n",
+			b:       "0\n2\n3\n5\n",
+			context: 1,
+			want:    "--- old\n+++ new\n@@ -1,4 +1,4 @@\n-1\n+0\n 2\n 3\n-4\n+5\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := unifiedDiff("old", "new", []byte(test.a), []byte(test.b), test.context)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("unifiedDiff returned unexpected diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
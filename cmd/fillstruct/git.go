@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// gitChangedFiles returns the absolute paths of files reported by
+// `git diff --name-only base`, including uncommitted changes, and of untracked
+// files that are not ignored. Paths have their symlinks resolved, see realPath.
+func gitChangedFiles(base string) (map[string]bool, error) {
+	root, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find git repository root: %w", err)
+	}
+	rootDir := realPath(strings.TrimSpace(string(root)))
+
+	diff := exec.Command("git", "diff", "--name-only", base)
+	diff.Dir = rootDir
+	changed, err := diff.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run git diff against %q: %w", base, err)
+	}
+
+	// Files that were never added are new to the change as well
+	lsFiles := exec.Command("git", "ls-files", "--others", "--exclude-standard")
+	lsFiles.Dir = rootDir
+	untracked, err := lsFiles.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list untracked files: %w", err)
+	}
+
+	files := make(map[string]bool)
+	for _, line := range bytes.Split(append(changed, untracked...), []byte("\n")) {
+		name := strings.TrimSpace(string(line))
+		if name == "" {
+			continue
+		}
+		files[realPath(filepath.Join(rootDir, name))] = true
+	}
+	return files, nil
+}
+
+// realPath returns path with its symlinks resolved, so that paths reported by git
+// and by the package loader compare equal. Paths that cannot be resolved, such as
+// those of deleted files, are returned unchanged.
+func realPath(path string) string {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return path
+	}
+	return resolved
+}
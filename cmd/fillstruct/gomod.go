@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/mod/modfile"
+)
+
+// moduleReplacements returns the replace directives of the go.mod file of the module
+// containing dir that replace a module with another module, mapping the replacement
+// module path to the path it replaces. Directives replacing a module with a local
+// directory keep the import paths and are skipped. It returns nil outside of a module.
+func moduleReplacements(dir string) (map[string]string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", dir, err)
+	}
+	for {
+		path := filepath.Join(dir, "go.mod")
+		content, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			parent := filepath.Dir(dir)
+			if parent == dir {
+				return nil, nil
+			}
+			dir = parent
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		f, err := modfile.Parse(path, content, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		replacements := make(map[string]string)
+		for _, r := range f.Replace {
+			if r.New.Version == "" {
+				continue
+			}
+			replacements[r.New.Path] = r.Old.Path
+		}
+		return replacements, nil
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestModuleReplacements(t *testing.T) {
+	dir := t.TempDir()
+	goMod := `module example.com/app
+
+go 1.25
+
+require (
+	example.com/lib v1.0.0
+	example.com/local v1.0.0
+)
+
+replace example.com/lib => example.com/fork/lib v1.2.0
+
+replace example.com/local => ../local
+`
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0o644); err != nil {
+		t.Fatalf("failed to write go.mod: %v", err)
+	}
+	sub := filepath.Join(dir, "internal", "models")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatalf("failed to create %s: %v", sub, err)
+	}
+
+	// The go.mod file is found from subdirectories of the module
+	got, err := moduleReplacements(sub)
+	if err != nil {
+		t.Fatalf("moduleReplacements returned unexpected error: %v", err)
+	}
+	want := map[string]string{"example.com/fork/lib": "example.com/lib"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("moduleReplacements returned unexpected result (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/token"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/nametake/fillstruct"
+)
+
+// JSON-RPC error codes used by the server
+const (
+	lspParseError     = -32700
+	lspMethodNotFound = -32601
+	lspInvalidParams  = -32602
+	lspRequestFailed  = -32803
+)
+
+type lspMessage struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  any              `json:"result,omitempty"`
+	Error   *lspError        `json:"error,omitempty"`
+}
+
+type lspError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type lspPosition struct {
+	Line      int `json:"line"`      // 0-based
+	Character int `json:"character"` // 0-based, in UTF-16 code units
+}
+
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+type lspTextEdit struct {
+	Range   lspRange `json:"range"`
+	NewText string   `json:"newText"`
+}
+
+// lspFillParams are the parameters of fillstruct/fillDocument and fillstruct/fillAtPosition.
+// Text is the current content of the document, which may differ from the file on disk.
+type lspFillParams struct {
+	TextDocument struct {
+		URI string `json:"uri"`
+	} `json:"textDocument"`
+	Text     string       `json:"text"`
+	Position *lspPosition `json:"position,omitempty"`
+}
+
+type lspFillResult struct {
+	Edits    []lspTextEdit `json:"edits"`
+	Warnings []string      `json:"warnings,omitempty"`
+}
+
+// serveLSP answers JSON-RPC requests framed with Content-Length headers, as in the
+// Language Server Protocol, until the exit notification or the end of the input.
+// Besides initialize and shutdown it handles fillstruct/fillDocument and
+// fillstruct/fillAtPosition, which return the text edits filling the document.
+func serveLSP(r io.Reader, w io.Writer, option *fillstruct.Option, tags string) error {
+	in := bufio.NewReader(r)
+	for {
+		body, err := readLSPMessage(in)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var msg lspMessage
+		if err := json.Unmarshal(body, &msg); err != nil {
+			// JSON-RPC requires the id of a request that cannot be parsed to be null
+			id := json.RawMessage("null")
+			if err := writeLSPMessage(w, &lspMessage{JSONRPC: "2.0", ID: &id, Error: &lspError{Code: lspParseError, Message: err.Error()}}); err != nil {
+				return err
+			}
+			continue
+		}
+		if msg.Method == "exit" {
+			return nil
+		}
+
+		result, rpcErr := handleLSPRequest(&msg, option, tags)
+		// Notifications are not answered
+		if msg.ID == nil {
+			continue
+		}
+		resp := &lspMessage{JSONRPC: "2.0", ID: msg.ID, Result: result, Error: rpcErr}
+		if rpcErr == nil && result == nil {
+			resp.Result = json.RawMessage("null")
+		}
+		if err := writeLSPMessage(w, resp); err != nil {
+			return err
+		}
+	}
+}
+
+func handleLSPRequest(msg *lspMessage, option *fillstruct.Option, tags string) (any, *lspError) {
+	switch msg.Method {
+	case "initialize":
+		return map[string]any{
+			"capabilities": map[string]any{},
+			"serverInfo":   map[string]any{"name": "fillstruct"},
+		}, nil
+	case "initialized", "shutdown":
+		return nil, nil
+	case "fillstruct/fillDocument", "fillstruct/fillAtPosition":
+		var params lspFillParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &lspError{Code: lspInvalidParams, Message: err.Error()}
+		}
+		if msg.Method == "fillstruct/fillAtPosition" && params.Position == nil {
+			return nil, &lspError{Code: lspInvalidParams, Message: "position is required"}
+		}
+		result, err := fillDocument(&params, option, tags)
+		if err != nil {
+			return nil, &lspError{Code: lspRequestFailed, Message: err.Error()}
+		}
+		return result, nil
+	default:
+		return nil, &lspError{Code: lspMethodNotFound, Message: fmt.Sprintf("method %q not found", msg.Method)}
+	}
+}
+
+// fillDocument fills the document content given in params. Unsaved changes are taken
+// into account since the content is loaded as an overlay.
+func fillDocument(params *lspFillParams, option *fillstruct.Option, tags string) (*lspFillResult, error) {
+	path, err := uriToPath(params.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	text := []byte(params.Text)
+
+	opt := *option
+	if params.Position != nil {
+		opt.Position = token.Position{
+			Line:   params.Position.Line + 1,
+			Column: byteColumn(text, params.Position.Line, params.Position.Character) + 1,
+		}
+	}
+	result, err := formatSource(context.Background(), path, text, &opt, tags)
+	if err != nil {
+		return nil, err
+	}
+	fill := &lspFillResult{Edits: []lspTextEdit{}}
+	for _, formatErr := range append(result.Errors, result.Warnings...) {
+		fill.Warnings = append(fill.Warnings, formatErr.String())
+	}
+	for _, edit := range result.TextEdits() {
+		fill.Edits = append(fill.Edits, lspTextEdit{
+			Range: lspRange{
+				Start: lspPositionAt(text, edit.Start),
+				End:   lspPositionAt(text, edit.End),
+			},
+			NewText: edit.NewText,
+		})
+	}
+	return fill, nil
+}
+
+// lspPositionAt converts a byte offset in text to an LSP position
+func lspPositionAt(text []byte, offset int) lspPosition {
+	before := text[:offset]
+	line := strings.Count(string(before), "\n")
+	lineStart := strings.LastIndex(string(before), "\n") + 1
+	return lspPosition{Line: line, Character: len(utf16.Encode([]rune(string(before[lineStart:]))))}
+}
+
+// byteColumn converts a 0-based UTF-16 character offset on the line to a 0-based byte offset
+func byteColumn(text []byte, line, character int) int {
+	lines := strings.SplitAfter(string(text), "\n")
+	if line >= len(lines) {
+		return character
+	}
+	column, units := 0, 0
+	for _, r := range lines[line] {
+		if units >= character {
+			break
+		}
+		units += utf16.RuneLen(r)
+		column += utf8.RuneLen(r)
+	}
+	return column
+}
+
+// uriToPath converts a file URI to an absolute path
+func uriToPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("invalid document uri %q: %v", uri, err)
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("unsupported document uri %q: only file URIs are supported", uri)
+	}
+	// Windows paths have a slash before the drive letter (file:///C:/x)
+	path := u.Path
+	if len(path) >= 3 && path[0] == '/' && path[2] == ':' {
+		if drive := path[1]; 'a' <= drive && drive <= 'z' || 'A' <= drive && drive <= 'Z' {
+			path = path[1:]
+		}
+	}
+	return filepath.FromSlash(path), nil
+}
+
+func readLSPMessage(r *bufio.Reader) ([]byte, error) {
+	length := -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) && line == "" && length == -1 {
+				return nil, io.EOF
+			}
+			return nil, fmt.Errorf("failed to read message header: %w", err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			length, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid Content-Length %q: %v", value, err)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("missing Content-Length header")
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, fmt.Errorf("failed to read message body: %w", err)
+	}
+	return body, nil
+}
+
+func writeLSPMessage(w io.Writer, msg *lspMessage) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+	if _, err := fmt.Fprintf(w, "Content-Length: %d\r\n\r\n%s", len(body), body); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	return nil
+}
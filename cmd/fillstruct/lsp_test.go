@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nametake/fillstruct"
+)
+
+func TestServeLSP(t *testing.T) {
+	input, err := os.ReadFile("../../testdata/cursor_position/input.go")
+	if err != nil {
+		t.Fatalf("failed to read input file: %v", err)
+	}
+	atPosition, err := os.ReadFile("../../testdata/cursor_position/golden.go")
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+
+	// The file on disk only declares the package, so filling relies on the document text
+	dir := setupModule(t, nil)
+	path := filepath.Join(dir, "fixtures.go")
+	if err := os.WriteFile(path, []byte("package cursor_position\n"), 0644); err != nil {
+		t.Fatalf("failed to write %q: %v", path, err)
+	}
+	uri := "file://" + filepath.ToSlash(path)
+
+	var in bytes.Buffer
+	requests := []map[string]any{
+		{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": map[string]any{}},
+		{"jsonrpc": "2.0", "method": "initialized", "params": map[string]any{}},
+		{"jsonrpc": "2.0", "id": 2, "method": "fillstruct/fillDocument", "params": map[string]any{
+			"textDocument": map[string]any{"uri": uri},
+			"text":         string(input),
+		}},
+		{"jsonrpc": "2.0", "id": 3, "method": "fillstruct/fillAtPosition", "params": map[string]any{
+			"textDocument": map[string]any{"uri": uri},
+			"text":         string(input),
+			"position":     map[string]any{"line": 15, "character": 20},
+		}},
+		{"jsonrpc": "2.0", "id": 4, "method": "unknown"},
+		{"jsonrpc": "2.0", "id": 5, "method": "shutdown"},
+		{"jsonrpc": "2.0", "method": "exit"},
+	}
+	for _, req := range requests {
+		body, err := json.Marshal(req)
+		if err != nil {
+			t.Fatalf("failed to encode request: %v", err)
+		}
+		in.WriteString("Content-Length: " + strconv.Itoa(len(body)) + "\r\n\r\n")
+		in.Write(body)
+	}
+
+	var out bytes.Buffer
+	if err := serveLSP(&in, &out, &fillstruct.Option{}, ""); err != nil {
+		t.Fatalf("serveLSP returned unexpected error: %v", err)
+	}
+
+	responses := make(map[string]lspMessage)
+	r := bufio.NewReader(&out)
+	for {
+		body, err := readLSPMessage(r)
+		if err != nil {
+			break
+		}
+		var msg lspMessage
+		if err := json.Unmarshal(body, &msg); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		responses[string(*msg.ID)] = msg
+	}
+	if len(responses) != 5 {
+		t.Fatalf("got %d responses, want 5 (notifications are not answered)", len(responses))
+	}
+
+	applyEdits := func(id string) string {
+		t.Helper()
+		msg := responses[id]
+		if msg.Error != nil {
+			t.Fatalf("request %s failed: %v", id, msg.Error.Message)
+		}
+		raw, err := json.Marshal(msg.Result)
+		if err != nil {
+			t.Fatalf("failed to encode result: %v", err)
+		}
+		var result lspFillResult
+		if err := json.Unmarshal(raw, &result); err != nil {
+			t.Fatalf("failed to decode result: %v", err)
+		}
+		if len(result.Edits) == 0 {
+			t.Fatalf("request %s returned no edits", id)
+		}
+		lines := strings.SplitAfter(string(input), "\n")
+		offset := func(p lspPosition) int {
+			n := 0
+			for _, line := range lines[:p.Line] {
+				n += len(line)
+			}
+			return n + p.Character
+		}
+		// Ranges refer to the original document, so the edits are applied from the end
+		got := string(input)
+		for i := len(result.Edits) - 1; i >= 0; i-- {
+			edit := result.Edits[i]
+			got = got[:offset(edit.Range.Start)] + edit.NewText + got[offset(edit.Range.End):]
+		}
+		return got
+	}
+
+	t.Run("fillDocument fills all literals", func(t *testing.T) {
+		got := applyEdits("2")
+		for _, want := range []string{`Person{Name: "first", Age: 0}`, `Lead: Person{Name: "lead", Age: 0},`, "Size: 0,"} {
+			if !strings.Contains(got, want) {
+				t.Errorf("filled document does not contain %q:\n%s", want, got)
+			}
+		}
+	})
+
+	t.Run("fillAtPosition fills the literal at the position", func(t *testing.T) {
+		if diff := cmp.Diff(string(atPosition), applyEdits("3")); diff != "" {
+			t.Errorf("output mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("unknown method is an error", func(t *testing.T) {
+		if msg := responses["4"]; msg.Error == nil || msg.Error.Code != lspMethodNotFound {
+			t.Errorf("unknown method response = %+v, want method not found error", msg)
+		}
+	})
+}
+
+func TestServeLSP_ParseError(t *testing.T) {
+	var in bytes.Buffer
+	in.WriteString("Content-Length: 5\r\n\r\n{bad}")
+	exit := `{"jsonrpc":"2.0","method":"exit"}`
+	in.WriteString("Content-Length: " + strconv.Itoa(len(exit)) + "\r\n\r\n" + exit)
+
+	var out bytes.Buffer
+	if err := serveLSP(&in, &out, &fillstruct.Option{}, ""); err != nil {
+		t.Fatalf("serveLSP returned unexpected error: %v", err)
+	}
+	body, err := readLSPMessage(bufio.NewReader(&out))
+	if err != nil {
+		t.Fatalf("failed to read response: %v", err)
+	}
+
+	var msg map[string]json.RawMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if id, ok := msg["id"]; !ok || string(id) != "null" {
+		t.Errorf("parse error response %s, want a null id", body)
+	}
+	var rpcErr lspError
+	if err := json.Unmarshal(msg["error"], &rpcErr); err != nil || rpcErr.Code != lspParseError {
+		t.Errorf("parse error response %s, want a parse error", body)
+	}
+}
+
+func TestURIToPath(t *testing.T) {
+	tests := []struct {
+		uri  string
+		want string
+	}{
+		{uri: "file:///home/user/main.go", want: filepath.FromSlash("/home/user/main.go")},
+		{uri: "file:///C:/src/main.go", want: filepath.FromSlash("C:/src/main.go")},
+		{uri: "file:///c%3A/src/main.go", want: filepath.FromSlash("c:/src/main.go")},
+		{uri: "file:///src/a%20b.go", want: filepath.FromSlash("/src/a b.go")},
+	}
+	for _, test := range tests {
+		got, err := uriToPath(test.uri)
+		if err != nil {
+			t.Errorf("uriToPath(%q) returned unexpected error: %v", test.uri, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("uriToPath(%q) = %q, want %q", test.uri, got, test.want)
+		}
+	}
+
+	if _, err := uriToPath("untitled:Untitled-1"); err == nil {
+		t.Error("uriToPath of a non-file URI returned no error")
+	}
+}
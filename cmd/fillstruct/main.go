@@ -1,12 +1,26 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"go/ast"
+	"go/token"
+	"go/types"
+	"io"
 	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
 	"strings"
 	"sync"
+	"text/tabwriter"
 
 	"github.com/nametake/fillstruct"
 	"golang.org/x/tools/go/packages"
@@ -26,12 +40,95 @@ func (a *arrayFlags) Set(value string) error {
 func main() {
 	var typeFlags arrayFlags
 	var defaultFlags arrayFlags
-	flag.Var(&typeFlags, "type", "target type (importpath.TypeName), can be specified multiple times")
+	var excludeFlags arrayFlags
+	flag.Var(&typeFlags, "type", "target type (importpath.TypeName, or TypeName for types in the processed packages), can be specified multiple times")
+	flag.Var(&excludeFlags, "exclude", "type never filled, even when targeted (importpath.TypeName or importpath.*), can be specified multiple times")
 	flag.Var(&defaultFlags, "default", "custom default value (format: TypeSpec=ConstantName), can be specified multiple times")
+	var interfaceDefaultFlags arrayFlags
+	flag.Var(&interfaceDefaultFlags, "interface-default", "expression filled for a named interface instead of nil (format: importpath.InterfaceName=Expression, e.g., io.Writer=io.Discard), can be specified multiple times")
+	tags := flag.String("tags", "", "comma-separated list of build tags to consider when loading packages")
+	onlyChanged := flag.Bool("only-changed", false, "only process files reported by git diff against -base and untracked files")
+	base := flag.String("base", "HEAD", "git ref to compare against when -only-changed is set")
+	includeGenerated := flag.Bool("include-generated", false, "also fill generated files outside of //fillstruct:begin and //fillstruct:end regions")
+	unknownPlaceholder := flag.String("unknown-placeholder", "", "expression used instead of nil for fields of unsupported types (each use is reported)")
+	var fieldIncludeFlags, fieldExcludeFlags arrayFlags
+	flag.Var(&fieldIncludeFlags, "field-include", "only add fields whose name matches the glob pattern (e.g., '*ID'), can be specified multiple times")
+	flag.Var(&fieldExcludeFlags, "field-exclude", "do not add fields whose name matches the glob pattern, can be specified multiple times")
+	excludeFieldRegexp := flag.String("exclude-field-regexp", "", "skip fields whose name matches the regular expression (e.g., '^XXX_')")
+	sampleValues := flag.Bool("sample-values", false, "fill plausible sample values guessed from field names (e.g., user@example.com for Email) instead of zero values")
+	stubFuncs := flag.Bool("stub-funcs", false, "fill function fields with a stub function literal returning zero values instead of nil")
+	nonNilPointers := flag.Bool("non-nil-pointers", false, "fill fields of pointer to named struct types with a pointer to an empty value (e.g., &Address{}) instead of nil")
+	includeUnexported := flag.Bool("include-unexported", false, "also fill unexported fields of structs declared in the package of the literal")
+	includeAnonymous := flag.Bool("include-anonymous", false, "also fill anonymous struct literals when -type is given")
+	tagKey := flag.String("tag-key", "fillstruct", "struct tag key for field directives (\"-\" skips the field, \"default=Name\" sets its default)")
+	recursive := flag.Bool("recursive", false, "fill the fields of added struct values recursively instead of leaving them empty")
+	recursiveDepth := flag.Int("recursive-depth", 0, "maximum number of nested levels filled by -recursive (0 means no limit)")
+	preserveOrder := flag.Bool("preserve-order", false, "keep existing fields in place and append missing fields in struct order")
+	convertPositional := flag.Bool("convert-positional", false, "convert incomplete positional literals to keyed form and fill them")
+	errorsJSON := flag.Bool("errors-json", false, "print errors and warnings as JSON objects, one per line")
+	interactive := flag.Bool("i", false, "show the diff for each changed file and ask before writing it (requires a terminal)")
+	noFormat := flag.Bool("no-format", false, "skip gofmt on the output (the result may not be gofmt-clean)")
+	normalizePaths := flag.Bool("normalize-paths", false, "match target and excluded types across vendored copies and modules replaced in go.mod by normalizing their import paths")
+	moduleRoot := flag.String("module-root", "", "directory to resolve -type import paths from (default: the directory of the pattern)")
+	configInit := flag.Bool("config-init", false, "write a "+configFileName+" with the default values to the current directory and exit")
+	lsp := flag.Bool("lsp", false, "serve fill requests from editors over stdin and stdout (JSON-RPC with LSP framing)")
+	var list bool
+	flag.BoolVar(&list, "list", false, "print the paths of files that would change instead of writing them, and exit with an error if any")
+	flag.BoolVar(&list, "l", false, "shorthand for -list")
+	var verbose bool
+	flag.BoolVar(&verbose, "verbose", false, "log each struct literal considered to stderr, with why it was skipped or the fields added")
+	flag.BoolVar(&verbose, "v", false, "shorthand for -verbose")
+	diff := flag.Bool("diff", false, "print a unified diff of the changes instead of writing files, and exit with an error if any file would change")
+	diffContext := flag.Int("diff-context", 3, "number of context lines in the diffs shown by -diff and -i")
+	jsonReport := flag.Bool("json", false, "print a JSON object per file with the fields added to each literal instead of writing files")
+	write := flag.Bool("w", false, "also write the changed files when -json is set")
+	coverage := flag.Bool("coverage", false, "report complete and incomplete literals per target type without modifying files")
+	parallel := flag.Int("parallel", 0, "maximum number of files formatted concurrently (0 means GOMAXPROCS)")
+	timeout := flag.Duration("timeout", 0, "stop and exit with an error when the run takes longer than this (e.g., 5m; 0 means no limit)")
+	stdinFilename := flag.String("stdin-filename", "stdin.go", "path the source read from stdin is filled as when the pattern is -, which determines its package")
+	followSymlinks := flag.Bool("follow-symlinks", true, "write symlinked files through to their target (skip them when false)")
 	flag.Parse()
 
-	// If no --type flag is specified, do nothing
-	if len(typeFlags) == 0 {
+	if *configInit {
+		path, err := initConfig(".")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error initializing config: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("wrote %s\n", path)
+		return
+	}
+
+	// Settings from the configuration file apply unless given as flags
+	configPath, err := findConfig(".")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error finding config: %v\n", err)
+		os.Exit(1)
+	}
+	if configPath != "" {
+		c, err := loadConfig(configPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		if err := applyConfig(flag.CommandLine, c, &typeFlags, &defaultFlags); err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *diffContext < 0 {
+		fmt.Fprintf(os.Stderr, "Error: -diff-context must not be negative\n")
+		os.Exit(1)
+	}
+	if *parallel < 0 {
+		fmt.Fprintf(os.Stderr, "Error: -parallel must not be negative\n")
+		os.Exit(1)
+	}
+
+	// If no --type flag is specified, do nothing. The server and stdin mode fill all
+	// literals instead, as their output replaces the input.
+	if len(typeFlags) == 0 && !*lsp && flag.Arg(0) != "-" {
 		os.Exit(0)
 	}
 
@@ -41,25 +138,35 @@ func main() {
 		pattern = args[0]
 	}
 
-	// Extract directory from pattern for resolving target types
-	dir := "."
-	if pattern != "./..." && pattern != "." {
-		dir = pattern
-		if len(dir) >= 4 && dir[len(dir)-4:] == "/..." {
-			dir = dir[:len(dir)-4]
-		}
-		if dir == "" {
-			dir = "."
-		}
+	// Resolve target types. Bare type names are resolved per package while formatting.
+	typeSpecs, typeNames := splitTypeSpecs(typeFlags)
+	typesPattern := pattern
+	if pattern == "-" {
+		typesPattern = filepath.Dir(*stdinFilename)
 	}
-
-	// Resolve target types
-	targetTypes, err := fillstruct.ResolveTargetTypes(typeFlags, dir)
+	targetTypes, err := fillstruct.ResolveTargetTypes(typeSpecs, targetTypesDir(typesPattern, *moduleRoot))
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error resolving target types: %v\n", err)
 		os.Exit(1)
 	}
 
+	excludeTypes, err := fillstruct.ResolveTargetTypes(excludeFlags, targetTypesDir(typesPattern, *moduleRoot))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error resolving excluded types: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Types of modules replaced with another module are matched by the module they replace,
+	// as listed in the go.mod file of the processed packages rather than of -module-root
+	var replacements map[string]string
+	if *normalizePaths {
+		replacements, err = moduleReplacements(targetTypesDir(typesPattern, ""))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading module replacements: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	// Parse default values
 	customDefaults, err := parseDefaultValues(defaultFlags)
 	if err != nil {
@@ -67,17 +174,164 @@ func main() {
 		os.Exit(1)
 	}
 
+	interfaceDefaults, err := parseDefaultValues(interfaceDefaultFlags)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing interface default values: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Compile field exclusion pattern
+	var excludeField *regexp.Regexp
+	if *excludeFieldRegexp != "" {
+		excludeField, err = regexp.Compile(*excludeFieldRegexp)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing exclude field regexp: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	for _, pattern := range append(fieldIncludeFlags, fieldExcludeFlags...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing field pattern %q: %v\n", pattern, err)
+			os.Exit(1)
+		}
+	}
+
 	option := &fillstruct.Option{
-		TargetTypes:    targetTypes,
-		CustomDefaults: customDefaults,
+		TargetTypes:        targetTypes,
+		ExcludeTypes:       excludeTypes,
+		CustomDefaults:     customDefaults,
+		InterfaceDefaults:  interfaceDefaults,
+		TargetTypeNames:    typeNames,
+		IncludeGenerated:   *includeGenerated,
+		UnknownPlaceholder: *unknownPlaceholder,
+		ExcludeFieldRegexp: excludeField,
+		FieldInclude:       fieldIncludeFlags,
+		FieldExclude:       fieldExcludeFlags,
+		SkipFinalFormat:    *noFormat,
+		ConvertPositional:  *convertPositional,
+		PreserveOrder:      *preserveOrder,
+		Recursive:          *recursive,
+		TagKey:             *tagKey,
+		IncludeUnexported:  *includeUnexported,
+		IncludeAnonymous:   *includeAnonymous,
+		SampleValues:       *sampleValues,
+		StubFuncs:          *stubFuncs,
+		NonNilPointers:     *nonNilPointers,
+		RecursiveDepth:     *recursiveDepth,
+		NormalizePaths:     *normalizePaths,
+		ModuleReplacements: replacements,
+	}
+
+	if *lsp {
+		if err := serveLSP(os.Stdin, os.Stdout, option, *tags); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	opts := &runOptions{
+		pattern:        pattern,
+		tags:           *tags,
+		followSymlinks: *followSymlinks,
+		errorsJSON:     *errorsJSON,
+		coverage:       *coverage,
+		json:           *jsonReport,
+		write:          *write,
+		list:           list,
+		diff:           *diff,
+		diffContext:    *diffContext,
+		parallel:       *parallel,
+		verbose:        verbose,
+		stdout:         os.Stdout,
+
+		wildcardPackages: wildcardPackages(typeSpecs),
+	}
+	if *interactive && !*diff && !list {
+		if isTerminal(os.Stdin) {
+			opts.interactive = true
+			opts.stdin = os.Stdin
+		} else {
+			fmt.Fprintln(os.Stderr, "stdin is not a terminal, ignoring -i")
+		}
+	}
+	if *onlyChanged {
+		files, err := gitChangedFiles(*base)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error listing changed files: %v\n", err)
+			os.Exit(1)
+		}
+		opts.files = files
 	}
 
-	if err := run(pattern, option); err != nil {
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+	if pattern == "-" {
+		err = fillStdin(ctx, os.Stdin, os.Stdout, *stdinFilename, option, *tags, *errorsJSON)
+	} else {
+		err = run(ctx, opts, option)
+	}
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = fmt.Errorf("timed out after %s: %w", *timeout, err)
+		}
 		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(1)
 	}
 }
 
+// targetTypesDir returns the directory to resolve target types from.
+// An explicit module root takes precedence over the directory of the pattern.
+func targetTypesDir(pattern, moduleRoot string) string {
+	if moduleRoot != "" {
+		return moduleRoot
+	}
+
+	// Extract directory from pattern
+	dir := "."
+	if pattern != "./..." && pattern != "." {
+		dir = pattern
+		if len(dir) >= 4 && dir[len(dir)-4:] == "/..." {
+			dir = dir[:len(dir)-4]
+		}
+		if dir == "" {
+			dir = "."
+		}
+	}
+	return dir
+}
+
+// wildcardPackages returns the import paths of type specifications for all types of a
+// package (e.g., "github.com/example/models.*")
+func wildcardPackages(typeSpecs []string) map[string]bool {
+	paths := make(map[string]bool)
+	for _, spec := range typeSpecs {
+		if importPath, ok := strings.CutSuffix(spec, ".*"); ok {
+			paths[importPath] = true
+		}
+	}
+	return paths
+}
+
+// splitTypeSpecs separates bare type names (e.g., "User") from
+// fully qualified type specifications (e.g., "github.com/example/foo.User")
+func splitTypeSpecs(specs []string) ([]string, []string) {
+	var typeSpecs, typeNames []string
+	for _, spec := range specs {
+		if token.IsIdentifier(spec) {
+			typeNames = append(typeNames, spec)
+			continue
+		}
+		typeSpecs = append(typeSpecs, spec)
+	}
+	return typeSpecs, typeNames
+}
+
 // parseDefaultValues parses default value specifications
 // Format: "TypeSpec=ConstantName"
 // TypeSpec can be:
@@ -109,58 +363,483 @@ func parseDefaultValues(specs []string) (map[string]string, error) {
 	return defaults, nil
 }
 
-func run(dir string, option *fillstruct.Option) error {
-	waitGroup := sync.WaitGroup{}
+// runOptions holds the command-line settings for a single run
+type runOptions struct {
+	pattern string
+	tags    string
+	files   map[string]bool // restricts processing to these absolute paths when non-nil
+
+	followSymlinks bool // write symlinked files through to their target instead of skipping them
+	parallel       int  // maximum number of files formatted concurrently, GOMAXPROCS when zero
+	errorsJSON     bool // print errors and warnings as JSON lines
+	verbose        bool // log the literals considered and what was filled to stderr
+
+	// wildcardPackages are the import paths given as "importpath.*". Their types are
+	// not reported when they match no literals, as most packages have unused types.
+	wildcardPackages map[string]bool
 
+	interactive bool      // show diffs and ask before writing each changed file
+	list        bool      // print the paths of changed files instead of writing them
+	diff        bool      // print diffs instead of writing files
+	diffContext int       // number of context lines in diffs
+	coverage    bool      // report complete and incomplete literals per type instead of writing
+	json        bool      // report the fields added per file as JSON lines instead of writing
+	write       bool      // also write changed files when json is set
+	stdin       io.Reader // answers to interactive prompts
+	stdout      io.Writer // diffs and prompts in interactive mode, and the coverage and JSON reports
+}
+
+// formatFile formats a single file. It is a variable so tests can slow it down.
+var formatFile = fillstruct.FormatContext
+
+// run fills the files matched by opts. When ctx is done, no further files are
+// dispatched, in-flight files are abandoned and the context error is returned.
+func run(ctx context.Context, opts *runOptions, option *fillstruct.Option) error {
+	// Env is left unset so a GOPACKAGESDRIVER from the environment (e.g., rules_go) is
+	// used. NeedDeps is required because drivers do not type check dependencies otherwise.
 	cfg := &packages.Config{
-		Mode:  packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo | packages.NeedFiles | packages.NeedImports,
-		Tests: true,
+		Mode:    packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo | packages.NeedFiles | packages.NeedImports | packages.NeedDeps,
+		Context: ctx,
+		Tests:   true,
+	}
+	if opts.tags != "" {
+		cfg.BuildFlags = []string{"-tags=" + opts.tags}
 	}
-	pkgs, err := packages.Load(cfg, dir)
+	pkgs, err := packages.Load(cfg, opts.pattern)
 	if err != nil {
-		return fmt.Errorf("failed to load packages: path = %s: %v", dir, err)
+		return fmt.Errorf("failed to load packages: path = %s: %v", opts.pattern, err)
 	}
 
-	errCount := 0
-	format := func(pkg *packages.Package, file *ast.File, wg *sync.WaitGroup) {
-		defer func() {
-			wg.Done()
-		}()
+	failedFiles := 0
+	// Workers record failures instead of exiting so that the other files are still
+	// processed and the caller decides how to exit
+	var failures []error
+	matched := make(map[string]bool) // target types with at least one literal
+	var mu sync.Mutex                // guards failedFiles, failures, matched, pending, reports, counts and writes to stderr
+	var pending []pendingWrite
+	var reports []*fileReport
+	counts := make(map[string]*literalCount)
+	for _, targetType := range option.TargetTypes {
+		counts[types.TypeString(targetType, nil)] = &literalCount{}
+	}
+	format := func(pkg *packages.Package, file *ast.File, path string) {
+		// Traces are buffered with the diagnostics of the file
+		var diagnostics bytes.Buffer
+		fileOption := option
+		if opts.verbose {
+			traced := *option
+			traced.Trace = func(pos token.Position, message string) {
+				fmt.Fprintf(&diagnostics, "%v: %s\n", pos, message)
+			}
+			fileOption = &traced
+		}
 
-		result, err := fillstruct.Format(pkg, file, option)
+		result, err := formatFile(ctx, pkg, file, fileOption)
+		if ctx.Err() != nil {
+			// Reported once by run
+			return
+		}
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "%v\n", err)
-			os.Exit(1)
+			mu.Lock()
+			failures = append(failures, err)
+			mu.Unlock()
+			return
 		}
 
+		// Diagnostics are buffered and written at once so that lines of files
+		// formatted concurrently do not interleave
+		for _, err := range result.Errors {
+			printFormatError(&diagnostics, err, severityError, opts.errorsJSON)
+		}
+		for _, warning := range result.Warnings {
+			printFormatError(&diagnostics, warning, severityWarning, opts.errorsJSON)
+		}
+		mu.Lock()
 		if len(result.Errors) > 0 {
-			for _, err := range result.Errors {
-				errCount += 1
-				fmt.Fprintf(os.Stderr, "%v\n", err)
+			failedFiles++
+		}
+		os.Stderr.Write(diagnostics.Bytes())
+		for _, target := range result.MatchedTargets {
+			matched[target] = true
+		}
+		mu.Unlock()
+
+		if opts.coverage {
+			mu.Lock()
+			for _, lit := range result.Literals {
+				count, ok := counts[lit.Type]
+				if !ok {
+					count = &literalCount{}
+					counts[lit.Type] = count
+				}
+				if len(lit.Missing) == 0 {
+					count.complete++
+				} else {
+					count.incomplete++
+				}
+			}
+			mu.Unlock()
+			return
+		}
+		if opts.json {
+			mu.Lock()
+			reports = append(reports, newFileReport(path, result))
+			mu.Unlock()
+			if !opts.write {
+				return
 			}
 		}
 		if !result.Changed {
 			return
 		}
 
-		if err := os.WriteFile(result.Path, result.Output, 0644); err != nil {
-			fmt.Fprintf(os.Stderr, "%v\n", err)
-			os.Exit(1)
+		if opts.list || opts.diff || opts.interactive {
+			// Diffs are printed sequentially, sorted by path, once all files are formatted
+			mu.Lock()
+			pending = append(pending, pendingWrite{path: path, output: result.Output})
+			mu.Unlock()
+			return
+		}
+
+		if err := os.WriteFile(path, result.Output, 0644); err != nil {
+			mu.Lock()
+			failures = append(failures, err)
+			mu.Unlock()
 		}
 	}
 
+	var jobs []formatJob
+	seen := make(map[string]bool)
 	for _, pkg := range pkgs {
 		for _, file := range pkg.Syntax {
-			waitGroup.Add(1)
-			go format(pkg, file, &waitGroup)
+			path := pkg.Fset.Position(file.Pos()).Filename
+			if opts.files != nil && !opts.files[realPath(path)] {
+				continue
+			}
+
+			realPath, err := resolvePath(path, opts.followSymlinks)
+			if err != nil {
+				return err
+			}
+			if realPath == "" {
+				fmt.Fprintf(os.Stderr, "skipping symlinked file %s\n", path)
+				continue
+			}
+			// The same file can be loaded more than once (test variants, symlinked paths)
+			if seen[realPath] {
+				continue
+			}
+			seen[realPath] = true
+
+			jobs = append(jobs, formatJob{pkg: pkg, file: file, path: realPath})
+		}
+	}
+
+	workers := opts.parallel
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	formatJobs(ctx, jobs, workers, func(job formatJob) {
+		format(job.pkg, job.file, job.path)
+	})
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("stopped before all files were processed: %w", err)
+	}
+	if len(failures) > 0 {
+		return errors.Join(failures...)
+	}
+
+	// Report target types without literals, which are likely typos. Runs restricted
+	// to some files are expected to miss types.
+	if opts.files == nil {
+		for _, target := range unmatchedTargets(option, matched, opts.wildcardPackages) {
+			printFormatError(os.Stderr, &fillstruct.FormatError{
+				Message: fmt.Sprintf("target type %s matched no literals", target),
+				PosText: opts.pattern,
+			}, severityWarning, opts.errorsJSON)
+		}
+	}
+
+	if opts.coverage {
+		if err := writeCoverage(opts.stdout, counts); err != nil {
+			return err
+		}
+	}
+
+	if opts.json {
+		if err := writeReports(opts.stdout, reports); err != nil {
+			return err
+		}
+	}
+
+	if opts.list || opts.diff {
+		if err := writeChanges(pending, opts.list, opts.diff, opts.diffContext, opts.stdout); err != nil {
+			return err
+		}
+	}
+
+	if opts.interactive {
+		if err := confirmWrites(pending, opts.diffContext, opts.stdin, opts.stdout); err != nil {
+			return err
 		}
 	}
 
+	if failedFiles > 0 {
+		return fmt.Errorf("failed to format %d files", failedFiles)
+	}
+	if (opts.list || opts.diff) && len(pending) > 0 {
+		return fmt.Errorf("%d files would be changed", len(pending))
+	}
+
+	return nil
+}
+
+// unmatchedTargets returns the target types of the option, in order, that are not in matched.
+// Excluded types and types of the wildcard packages are left out.
+func unmatchedTargets(option *fillstruct.Option, matched map[string]bool, wildcards map[string]bool) []string {
+	var unmatched []string
+	excluded := make(map[string]bool)
+	for _, excludeType := range option.ExcludeTypes {
+		excluded[types.TypeString(excludeType, nil)] = true
+	}
+	for _, targetType := range option.TargetTypes {
+		if wildcards[targetType.Obj().Pkg().Path()] || excluded[types.TypeString(targetType, nil)] {
+			continue
+		}
+		if target := types.TypeString(targetType, nil); !matched[target] {
+			unmatched = append(unmatched, target)
+		}
+	}
+	for _, name := range option.TargetTypeNames {
+		if !matched[name] {
+			unmatched = append(unmatched, name)
+		}
+	}
+	return unmatched
+}
+
+// formatJobs runs format for the jobs on a fixed number of workers instead of one
+// goroutine per file, which keeps scheduling and memory overhead flat on large
+// repositories. When ctx is done, the remaining jobs are not dispatched.
+func formatJobs(ctx context.Context, jobs []formatJob, workers int, format func(job formatJob)) {
+	queue := make(chan formatJob)
+	var waitGroup sync.WaitGroup
+	for range min(workers, len(jobs)) {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for job := range queue {
+				format(job)
+			}
+		}()
+	}
+dispatch:
+	for _, job := range jobs {
+		select {
+		case queue <- job:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(queue)
 	waitGroup.Wait()
+}
 
-	if errCount > 0 {
-		return fmt.Errorf("failed to format %d files", errCount)
+// formatJob is a file queued for formatting
+type formatJob struct {
+	pkg  *packages.Package
+	file *ast.File
+	path string // real path the output is written to
+}
+
+// resolvePath returns the real path the output for the file should be written to.
+// It returns an empty path when the file is a symlink and symlinks are not followed.
+func resolvePath(path string, followSymlinks bool) (string, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat %s: %v", path, err)
+	}
+	if info.Mode()&os.ModeSymlink != 0 && !followSymlinks {
+		return "", nil
 	}
 
+	realPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve symlinks: path = %s: %v", path, err)
+	}
+	return realPath, nil
+}
+
+// literalCount counts the literals of a type for the coverage report
+type literalCount struct {
+	complete   int
+	incomplete int
+}
+
+// writeCoverage prints the literal counts per type, sorted by type
+func writeCoverage(w io.Writer, counts map[string]*literalCount) error {
+	typeNames := make([]string, 0, len(counts))
+	for typeName := range counts {
+		typeNames = append(typeNames, typeName)
+	}
+	sort.Strings(typeNames)
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "TYPE\tCOMPLETE\tINCOMPLETE")
+	for _, typeName := range typeNames {
+		fmt.Fprintf(tw, "%s\t%d\t%d\n", typeName, counts[typeName].complete, counts[typeName].incomplete)
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write coverage report: %w", err)
+	}
 	return nil
 }
+
+// fileReport is the JSON report of a file printed by -json
+type fileReport struct {
+	Path     string           `json:"path"`
+	Changed  bool             `json:"changed"`
+	Literals []*literalReport `json:"literals"`
+}
+
+// literalReport lists the fields added to a literal
+type literalReport struct {
+	Type     string         `json:"type"`
+	Position token.Position `json:"position"`
+	Added    []string       `json:"added"`
+}
+
+// newFileReport returns the report of the file, listing only the literals that were filled
+func newFileReport(path string, result *fillstruct.FormatResult) *fileReport {
+	report := &fileReport{Path: path, Changed: result.Changed, Literals: []*literalReport{}}
+	for _, lit := range result.Literals {
+		if len(lit.Missing) == 0 {
+			continue
+		}
+		report.Literals = append(report.Literals, &literalReport{
+			Type:     lit.Type,
+			Position: lit.Position,
+			Added:    lit.Missing,
+		})
+	}
+	return report
+}
+
+// writeReports prints the reports as JSON objects, one per line, sorted by path
+func writeReports(w io.Writer, reports []*fileReport) error {
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Path < reports[j].Path
+	})
+	enc := json.NewEncoder(w)
+	for _, report := range reports {
+		if err := enc.Encode(report); err != nil {
+			return fmt.Errorf("failed to write JSON report: %w", err)
+		}
+	}
+	return nil
+}
+
+// Severities of the printed errors
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// printFormatError prints the error in human-readable form, with a prefix for warnings,
+// or as a single line of JSON with its severity
+func printFormatError(w io.Writer, formatErr *fillstruct.FormatError, severity string, asJSON bool) {
+	prefix := ""
+	if severity == severityWarning {
+		prefix = "warning: "
+	}
+	if !asJSON {
+		fmt.Fprintf(w, "%s%v\n", prefix, formatErr)
+		return
+	}
+
+	// The severity depends on the list holding the error, so it is added to its object
+	var object map[string]json.RawMessage
+	b, err := json.Marshal(formatErr)
+	if err == nil {
+		err = json.Unmarshal(b, &object)
+	}
+	if err == nil {
+		object["severity"], _ = json.Marshal(severity)
+		b, err = json.Marshal(object)
+	}
+	if err != nil {
+		fmt.Fprintf(w, "%s%v\n", prefix, formatErr)
+		return
+	}
+	fmt.Fprintf(w, "%s\n", b)
+}
+
+// pendingWrite is a changed file waiting for confirmation in interactive mode
+type pendingWrite struct {
+	path   string
+	output []byte
+}
+
+// writeChanges prints the path, the diff or both for each pending file, sorted by path,
+// without writing it
+func writeChanges(pending []pendingWrite, list, diff bool, diffContext int, stdout io.Writer) error {
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].path < pending[j].path
+	})
+
+	for _, p := range pending {
+		if list {
+			fmt.Fprintln(stdout, p.path)
+		}
+		if !diff {
+			continue
+		}
+		original, err := os.ReadFile(p.path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %v", p.path, err)
+		}
+		fmt.Fprint(stdout, unifiedDiff(p.path+".orig", p.path, original, p.output, diffContext))
+	}
+	return nil
+}
+
+// confirmWrites prints the diff for each pending file and writes only the files
+// the user confirms. Reaching the end of the input declines the remaining files.
+func confirmWrites(pending []pendingWrite, diffContext int, stdin io.Reader, stdout io.Writer) error {
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].path < pending[j].path
+	})
+
+	reader := bufio.NewReader(stdin)
+	for _, p := range pending {
+		original, err := os.ReadFile(p.path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %v", p.path, err)
+		}
+		fmt.Fprint(stdout, unifiedDiff(p.path+".orig", p.path, original, p.output, diffContext))
+		fmt.Fprintf(stdout, "Apply changes to %s? [y/N] ", p.path)
+
+		answer, err := reader.ReadString('\n')
+		if err != nil && answer == "" {
+			fmt.Fprintln(stdout)
+			return nil
+		}
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+			if err := os.WriteFile(p.path, p.output, 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %v", p.path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// isTerminal reports whether the file is a character device such as a terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,1005 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nametake/fillstruct"
+	"golang.org/x/tools/go/packages"
+)
+
+// setupModule copies the given files into a temporary module and changes the
+// working directory to it
+func setupModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/fixtures\n\ngo 1.25.5\n"), 0644); err != nil {
+		t.Fatalf("failed to write go.mod: %v", err)
+	}
+	for name, src := range files {
+		content, err := os.ReadFile(src)
+		if err != nil {
+			t.Fatalf("failed to read %q: %v", src, err)
+		}
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755); err != nil {
+			t.Fatalf("failed to create directory for %q: %v", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0644); err != nil {
+			t.Fatalf("failed to write %q: %v", name, err)
+		}
+	}
+
+	t.Chdir(dir)
+	return dir
+}
+
+func TestRun_Tags(t *testing.T) {
+	input, err := filepath.Abs("../../testdata/build_tag/input.go")
+	if err != nil {
+		t.Fatalf("failed to get absolute path: %v", err)
+	}
+	golden, err := os.ReadFile("../../testdata/build_tag/golden.go")
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+	original, err := os.ReadFile(input)
+	if err != nil {
+		t.Fatalf("failed to read input file: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		tags string
+		want []byte
+	}{
+		{
+			name: "file guarded by a build tag is not loaded without -tags",
+			tags: "",
+			want: original,
+		},
+		{
+			name: "file guarded by a build tag is filled with -tags and keeps its header",
+			tags: "fixtures",
+			want: golden,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := setupModule(t, map[string]string{"fixtures.go": input})
+
+			if err := run(t.Context(), &runOptions{pattern: "./...", tags: test.tags}, &fillstruct.Option{}); err != nil {
+				t.Fatalf("run returned unexpected error: %v", err)
+			}
+
+			got, err := os.ReadFile(filepath.Join(dir, "fixtures.go"))
+			if err != nil {
+				t.Fatalf("failed to read output: %v", err)
+			}
+			if diff := cmp.Diff(string(test.want), string(got)); diff != "" {
+				t.Errorf("run returned unexpected output (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRun_OnlyFiles(t *testing.T) {
+	simple, err := filepath.Abs("../../testdata/simple/input.go")
+	if err != nil {
+		t.Fatalf("failed to get absolute path: %v", err)
+	}
+	pointer, err := filepath.Abs("../../testdata/pointer/input.go")
+	if err != nil {
+		t.Fatalf("failed to get absolute path: %v", err)
+	}
+	golden, err := filepath.Abs("../../testdata/simple/golden.go")
+	if err != nil {
+		t.Fatalf("failed to get absolute path: %v", err)
+	}
+
+	dir := setupModule(t, map[string]string{
+		"simple/simple.go":   simple,
+		"pointer/pointer.go": pointer,
+	})
+
+	opts := &runOptions{
+		pattern: "./...",
+		files: map[string]bool{
+			filepath.Join(dir, "simple/simple.go"): true,
+		},
+	}
+	if err := run(t.Context(), opts, &fillstruct.Option{}); err != nil {
+		t.Fatalf("run returned unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		golden string
+	}{
+		{
+			name:   "listed file is processed",
+			path:   "simple/simple.go",
+			golden: golden,
+		},
+		{
+			name:   "unlisted file is left untouched",
+			path:   "pointer/pointer.go",
+			golden: pointer,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			want, err := os.ReadFile(test.golden)
+			if err != nil {
+				t.Fatalf("failed to read %q: %v", test.golden, err)
+			}
+			got, err := os.ReadFile(filepath.Join(dir, test.path))
+			if err != nil {
+				t.Fatalf("failed to read output: %v", err)
+			}
+			if diff := cmp.Diff(string(want), string(got)); diff != "" {
+				t.Errorf("unexpected content of %q (-want +got):\n%s", test.path, diff)
+			}
+		})
+	}
+}
+
+func TestGitChangedFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	tmp, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to resolve temp dir: %v", err)
+	}
+	dir := filepath.Join(tmp, "repo")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatalf("failed to create %s: %v", dir, err)
+	}
+	t.Chdir(dir)
+
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	writeFile := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %q: %v", name, err)
+		}
+	}
+	writeFile("changed.go", "package p\n")
+	writeFile("unchanged.go", "package p\n")
+	writeFile(".gitignore", "ignored.go\n")
+	git("init", "-q")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+	writeFile("changed.go", "package p\n\nvar _ = 1\n")
+	writeFile("added.go", "package p\n")
+	writeFile("ignored.go", "package p\n")
+
+	// Run from a symlink to the checkout, the paths are reported resolved
+	link := filepath.Join(tmp, "link")
+	if err := os.Symlink(dir, link); err != nil {
+		t.Skipf("symlinks are not supported: %v", err)
+	}
+	t.Chdir(link)
+
+	got, err := gitChangedFiles("HEAD")
+	if err != nil {
+		t.Fatalf("gitChangedFiles returned unexpected error: %v", err)
+	}
+
+	want := map[string]bool{
+		filepath.Join(dir, "added.go"):   true,
+		filepath.Join(dir, "changed.go"): true,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("gitChangedFiles returned unexpected files (-want +got):\n%s", diff)
+	}
+}
+
+func TestRun_Symlinks(t *testing.T) {
+	input, err := filepath.Abs("../../testdata/simple/input.go")
+	if err != nil {
+		t.Fatalf("failed to get absolute path: %v", err)
+	}
+	golden, err := filepath.Abs("../../testdata/simple/golden.go")
+	if err != nil {
+		t.Fatalf("failed to get absolute path: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		followSymlinks bool
+		golden         string
+	}{
+		{
+			name:           "symlinked file is written through to its target",
+			followSymlinks: true,
+			golden:         golden,
+		},
+		{
+			name:           "symlinked file is skipped when symlinks are not followed",
+			followSymlinks: false,
+			golden:         input,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := setupModule(t, map[string]string{"shared/person.go.txt": input})
+			if err := os.MkdirAll(filepath.Join(dir, "app"), 0755); err != nil {
+				t.Fatalf("failed to create app directory: %v", err)
+			}
+			link := filepath.Join(dir, "app/person.go")
+			if err := os.Symlink("../shared/person.go.txt", link); err != nil {
+				t.Skipf("symlinks are not supported: %v", err)
+			}
+
+			opts := &runOptions{pattern: "./app/...", followSymlinks: test.followSymlinks}
+			if err := run(t.Context(), opts, &fillstruct.Option{}); err != nil {
+				t.Fatalf("run returned unexpected error: %v", err)
+			}
+
+			info, err := os.Lstat(link)
+			if err != nil {
+				t.Fatalf("failed to stat symlink: %v", err)
+			}
+			if info.Mode()&os.ModeSymlink == 0 {
+				t.Errorf("symlink %q was replaced with a regular file", link)
+			}
+
+			want, err := os.ReadFile(test.golden)
+			if err != nil {
+				t.Fatalf("failed to read %q: %v", test.golden, err)
+			}
+			got, err := os.ReadFile(filepath.Join(dir, "shared/person.go.txt"))
+			if err != nil {
+				t.Fatalf("failed to read output: %v", err)
+			}
+			if diff := cmp.Diff(string(want), string(got)); diff != "" {
+				t.Errorf("unexpected content of symlink target (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRun_Interactive(t *testing.T) {
+	simple, err := filepath.Abs("../../testdata/simple/input.go")
+	if err != nil {
+		t.Fatalf("failed to get absolute path: %v", err)
+	}
+	pointer, err := filepath.Abs("../../testdata/pointer/input.go")
+	if err != nil {
+		t.Fatalf("failed to get absolute path: %v", err)
+	}
+	pointerGolden, err := filepath.Abs("../../testdata/pointer/golden.go")
+	if err != nil {
+		t.Fatalf("failed to get absolute path: %v", err)
+	}
+
+	dir := setupModule(t, map[string]string{
+		"simple/simple.go":   simple,
+		"pointer/pointer.go": pointer,
+	})
+
+	// Files are confirmed in path order: pointer/pointer.go, then simple/simple.go
+	var stdout bytes.Buffer
+	opts := &runOptions{
+		pattern:     "./...",
+		interactive: true,
+		stdin:       strings.NewReader("y\nn\n"),
+		stdout:      &stdout,
+	}
+	if err := run(t.Context(), opts, &fillstruct.Option{}); err != nil {
+		t.Fatalf("run returned unexpected error: %v", err)
+	}
+
+	if got := strings.Count(stdout.String(), "Apply changes to "); got != 2 {
+		t.Errorf("got %d prompts, want 2:\n%s", got, stdout.String())
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		golden string
+	}{
+		{
+			name:   "confirmed file is written",
+			path:   "pointer/pointer.go",
+			golden: pointerGolden,
+		},
+		{
+			name:   "declined file is left untouched",
+			path:   "simple/simple.go",
+			golden: simple,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			want, err := os.ReadFile(test.golden)
+			if err != nil {
+				t.Fatalf("failed to read %q: %v", test.golden, err)
+			}
+			got, err := os.ReadFile(filepath.Join(dir, test.path))
+			if err != nil {
+				t.Fatalf("failed to read output: %v", err)
+			}
+			if diff := cmp.Diff(string(want), string(got)); diff != "" {
+				t.Errorf("unexpected content of %q (-want +got):\n%s", test.path, diff)
+			}
+		})
+	}
+}
+
+func TestTargetTypesDir_ModuleRoot(t *testing.T) {
+	input, err := filepath.Abs("../../testdata/simple/input.go")
+	if err != nil {
+		t.Fatalf("failed to get absolute path: %v", err)
+	}
+	root := setupModule(t, map[string]string{"simple/simple.go": input})
+
+	// Run from a directory outside the module so the pattern directory cannot
+	// resolve the module's import paths
+	outside := t.TempDir()
+	t.Chdir(outside)
+
+	spec := "example.com/fixtures/simple.Person"
+	if _, err := fillstruct.ResolveTargetTypes([]string{spec}, targetTypesDir("./...", "")); err == nil {
+		t.Fatalf("ResolveTargetTypes from %q unexpectedly succeeded", outside)
+	}
+
+	targetTypes, err := fillstruct.ResolveTargetTypes([]string{spec}, targetTypesDir("./...", root))
+	if err != nil {
+		t.Fatalf("ResolveTargetTypes with module root returned unexpected error: %v", err)
+	}
+	if len(targetTypes) != 1 || targetTypes[0].Obj().Name() != "Person" {
+		t.Errorf("ResolveTargetTypes = %v, want [Person]", targetTypes)
+	}
+}
+
+func TestRun_PackagesDriver(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake driver is a shell script")
+	}
+
+	input, err := filepath.Abs("../../testdata/computed_values/input.go")
+	if err != nil {
+		t.Fatalf("failed to get absolute path: %v", err)
+	}
+	golden, err := os.ReadFile("../../testdata/computed_values/golden.go")
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+	dir := setupModule(t, map[string]string{"fixtures.go": input})
+
+	// The driver records that it was invoked and lets go list handle the request
+	marker := filepath.Join(t.TempDir(), "invoked")
+	driver := filepath.Join(t.TempDir(), "driver.sh")
+	script := "#!/bin/sh\ncat > /dev/null\ntouch '" + marker + "'\necho '{\"NotHandled\": true}'\n"
+	if err := os.WriteFile(driver, []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write driver: %v", err)
+	}
+	t.Setenv("GOPACKAGESDRIVER", driver)
+
+	if err := run(t.Context(), &runOptions{pattern: "./..."}, &fillstruct.Option{}); err != nil {
+		t.Fatalf("run returned unexpected error: %v", err)
+	}
+
+	if _, err := os.Stat(marker); err != nil {
+		t.Errorf("GOPACKAGESDRIVER was not invoked: %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "fixtures.go"))
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	if diff := cmp.Diff(string(golden), string(got)); diff != "" {
+		t.Errorf("output mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// writeSyntheticModule writes a module with the given number of packages, each with
+// the given number of files containing literals with missing fields
+func writeSyntheticModule(tb testing.TB, dir string, numPackages, numFiles int) {
+	tb.Helper()
+
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/synthetic\n\ngo 1.25.5\n"), 0644); err != nil {
+		tb.Fatalf("failed to write go.mod: %v", err)
+	}
+	for p := 0; p < numPackages; p++ {
+		pkgDir := filepath.Join(dir, fmt.Sprintf("pkg%d", p))
+		if err := os.MkdirAll(pkgDir, 0755); err != nil {
+			tb.Fatalf("failed to create package directory: %v", err)
+		}
+		for f := 0; f < numFiles; f++ {
+			var sb strings.Builder
+			fmt.Fprintf(&sb, "package pkg%d\n\n", p)
+			fmt.Fprintf(&sb, "type Config%d struct {\n\tName    string\n\tPort    int\n\tEnabled bool\n\tTags    []string\n}\n\n", f)
+			fmt.Fprintf(&sb, "func New%d() []Config%d {\n\treturn []Config%d{\n", f, f, f)
+			for i := 0; i < 10; i++ {
+				fmt.Fprintf(&sb, "\t\t{Name: %q},\n", fmt.Sprint("config", i))
+			}
+			sb.WriteString("\t}\n}\n")
+			if err := os.WriteFile(filepath.Join(pkgDir, fmt.Sprintf("file%d.go", f)), []byte(sb.String()), 0644); err != nil {
+				tb.Fatalf("failed to write file: %v", err)
+			}
+		}
+	}
+}
+
+// BenchmarkFormatJobs measures the formatting phase of run on packages loaded once, as
+// loading dominates a whole run. The files are formatted by one worker, by the default
+// pool and by one goroutine per file, as run did before the pool.
+func BenchmarkFormatJobs(b *testing.B) {
+	const numPackages, numFiles = 20, 10
+
+	dir := b.TempDir()
+	writeSyntheticModule(b, dir, numPackages, numFiles)
+	cfg := &packages.Config{
+		Mode: packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo | packages.NeedFiles | packages.NeedImports | packages.NeedDeps,
+		Dir:  dir,
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		b.Fatalf("failed to load packages: %v", err)
+	}
+	var jobs []formatJob
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Syntax {
+			jobs = append(jobs, formatJob{pkg: pkg, file: file, path: pkg.Fset.Position(file.Pos()).Filename})
+		}
+	}
+
+	benchmarks := []struct {
+		name    string
+		workers int
+	}{
+		{name: "one worker", workers: 1},
+		{name: "GOMAXPROCS workers", workers: runtime.GOMAXPROCS(0)},
+		{name: "goroutine per file", workers: len(jobs)},
+	}
+	for _, bench := range benchmarks {
+		b.Run(bench.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				formatJobs(b.Context(), jobs, bench.workers, func(job formatJob) {
+					if _, err := fillstruct.FormatContext(b.Context(), job.pkg, job.file, &fillstruct.Option{}); err != nil {
+						b.Errorf("FormatContext(%q) returned unexpected error: %v", job.path, err)
+					}
+				})
+			}
+			b.ReportMetric(float64(len(jobs)*b.N)/b.Elapsed().Seconds(), "files/s")
+		})
+	}
+}
+
+func TestRun_Timeout(t *testing.T) {
+	input, err := filepath.Abs("../../testdata/simple/input.go")
+	if err != nil {
+		t.Fatalf("failed to get absolute path: %v", err)
+	}
+	original, err := os.ReadFile(input)
+	if err != nil {
+		t.Fatalf("failed to read input file: %v", err)
+	}
+	dir := setupModule(t, map[string]string{"a/fixtures.go": input, "b/fixtures.go": input})
+
+	// Formatting blocks until the run is canceled
+	var calls atomic.Int32
+	formatFile = func(ctx context.Context, pkg *packages.Package, file *ast.File, option *fillstruct.Option) (*fillstruct.FormatResult, error) {
+		calls.Add(1)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	t.Cleanup(func() { formatFile = fillstruct.FormatContext })
+
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+
+	err = run(ctx, &runOptions{pattern: "./..."}, &fillstruct.Option{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("run returned %v, want %v", err, context.DeadlineExceeded)
+	}
+	if got, max := calls.Load(), int32(runtime.GOMAXPROCS(0)); got == 0 || got > max {
+		t.Errorf("formatFile was called %d times, want between 1 and %d", got, max)
+	}
+	for _, name := range []string{"a/fixtures.go", "b/fixtures.go"} {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("failed to read output: %v", err)
+		}
+		if diff := cmp.Diff(string(original), string(got)); diff != "" {
+			t.Errorf("%s was modified after the timeout (-want +got):\n%s", name, diff)
+		}
+	}
+}
+
+func TestRun_Coverage(t *testing.T) {
+	input, err := filepath.Abs("../../testdata/literal_coverage/input.go")
+	if err != nil {
+		t.Fatalf("failed to get absolute path: %v", err)
+	}
+	original, err := os.ReadFile(input)
+	if err != nil {
+		t.Fatalf("failed to read input file: %v", err)
+	}
+	dir := setupModule(t, map[string]string{"fixtures.go": input})
+
+	var stdout bytes.Buffer
+	opts := &runOptions{pattern: "./...", coverage: true, stdout: &stdout}
+	if err := run(t.Context(), opts, &fillstruct.Option{TargetTypeNames: []string{"Person"}}); err != nil {
+		t.Fatalf("run returned unexpected error: %v", err)
+	}
+
+	want := "TYPE                         COMPLETE  INCOMPLETE\n" +
+		"example.com/fixtures.Person  2         2\n"
+	if diff := cmp.Diff(want, stdout.String()); diff != "" {
+		t.Errorf("coverage report mismatch (-want +got):\n%s", diff)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dir, "fixtures.go"))
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	if diff := cmp.Diff(string(original), string(got)); diff != "" {
+		t.Errorf("file was modified in coverage mode (-want +got):\n%s", diff)
+	}
+}
+
+func TestRun_DiffContext(t *testing.T) {
+	input, err := filepath.Abs("../../testdata/simple/input.go")
+	if err != nil {
+		t.Fatalf("failed to get absolute path: %v", err)
+	}
+
+	// The fill inserts a single line after line 11 of the 14 line file
+	tests := []struct {
+		name        string
+		diffContext int
+		want        int
+	}{
+		{name: "no context", diffContext: 0, want: 0},
+		{name: "one line", diffContext: 1, want: 2},
+		{name: "default", diffContext: 3, want: 6},
+		{name: "more than available after the change", diffContext: 10, want: 13},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			setupModule(t, map[string]string{"fixtures.go": input})
+
+			var stdout bytes.Buffer
+			opts := &runOptions{
+				pattern:     "./...",
+				interactive: true,
+				diffContext: test.diffContext,
+				stdin:       strings.NewReader("n\n"),
+				stdout:      &stdout,
+			}
+			if err := run(t.Context(), opts, &fillstruct.Option{}); err != nil {
+				t.Fatalf("run returned unexpected error: %v", err)
+			}
+
+			got := 0
+			for _, line := range strings.Split(stdout.String(), "\n") {
+				if strings.HasPrefix(line, " ") {
+					got++
+				}
+			}
+			if got != test.want {
+				t.Errorf("got %d context lines, want %d:\n%s", got, test.want, stdout.String())
+			}
+		})
+	}
+}
+
+func TestRun_Diff(t *testing.T) {
+	input, err := filepath.Abs("../../testdata/simple/input.go")
+	if err != nil {
+		t.Fatalf("failed to get absolute path: %v", err)
+	}
+	golden, err := filepath.Abs("../../testdata/simple/golden.go")
+	if err != nil {
+		t.Fatalf("failed to get absolute path: %v", err)
+	}
+	dir := setupModule(t, map[string]string{"a/fixtures.go": input, "b/fixtures.go": input, "c/done.go": golden})
+	original, err := os.ReadFile(input)
+	if err != nil {
+		t.Fatalf("failed to read input: %v", err)
+	}
+
+	var stdout bytes.Buffer
+	opts := &runOptions{pattern: "./...", diff: true, diffContext: 3, stdout: &stdout}
+	err = run(t.Context(), opts, &fillstruct.Option{})
+	if err == nil || !strings.Contains(err.Error(), "2 files would be changed") {
+		t.Errorf("run error = %v, want the number of files that would change", err)
+	}
+
+	// Both changed files are listed in order with their path in the header
+	out := stdout.String()
+	pathA, pathB := filepath.Join(dir, "a", "fixtures.go"), filepath.Join(dir, "b", "fixtures.go")
+	headerA, headerB := strings.Index(out, "+++ "+pathA+"\n"), strings.Index(out, "+++ "+pathB+"\n")
+	if headerA < 0 || headerB < headerA {
+		t.Errorf("diff headers missing or out of order:\n%s", out)
+	}
+	if strings.Contains(out, "done.go") {
+		t.Errorf("diff includes an unchanged file:\n%s", out)
+	}
+
+	// Nothing is written
+	for _, path := range []string{pathA, pathB} {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read output: %v", err)
+		}
+		if !bytes.Equal(content, original) {
+			t.Errorf("%s was modified", path)
+		}
+	}
+
+	// Nothing to change is a success with no output
+	stdout.Reset()
+	opts.pattern = "./c"
+	if err := run(t.Context(), opts, &fillstruct.Option{}); err != nil {
+		t.Errorf("run returned unexpected error: %v", err)
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("unexpected diff output:\n%s", stdout.String())
+	}
+}
+
+func TestRun_List(t *testing.T) {
+	input, err := filepath.Abs("../../testdata/simple/input.go")
+	if err != nil {
+		t.Fatalf("failed to get absolute path: %v", err)
+	}
+	golden, err := filepath.Abs("../../testdata/simple/golden.go")
+	if err != nil {
+		t.Fatalf("failed to get absolute path: %v", err)
+	}
+	dir := setupModule(t, map[string]string{"b/fixtures.go": input, "a/fixtures.go": input, "c/done.go": golden})
+	pathA, pathB := filepath.Join(dir, "a", "fixtures.go"), filepath.Join(dir, "b", "fixtures.go")
+
+	var stdout bytes.Buffer
+	opts := &runOptions{pattern: "./...", list: true, stdout: &stdout}
+	err = run(t.Context(), opts, &fillstruct.Option{})
+	if err == nil || !strings.Contains(err.Error(), "2 files would be changed") {
+		t.Errorf("run error = %v, want the number of files that would change", err)
+	}
+	if diff := cmp.Diff(pathA+"\n"+pathB+"\n", stdout.String()); diff != "" {
+		t.Errorf("listed files mismatch (-want +got):\n%s", diff)
+	}
+	original, err := os.ReadFile(input)
+	if err != nil {
+		t.Fatalf("failed to read input: %v", err)
+	}
+	if content, err := os.ReadFile(pathA); err != nil || !bytes.Equal(content, original) {
+		t.Errorf("%s was modified", pathA)
+	}
+
+	// With -diff, each path is followed by its diff
+	stdout.Reset()
+	opts.diff = true
+	if err := run(t.Context(), opts, &fillstruct.Option{}); err == nil {
+		t.Errorf("run returned no error")
+	}
+	out := stdout.String()
+	if !strings.HasPrefix(out, pathA+"\n--- "+pathA+".orig\n") || !strings.Contains(out, "\n"+pathB+"\n--- "+pathB+".orig\n") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
+
+func TestRun_JSON(t *testing.T) {
+	input, err := filepath.Abs("../../testdata/simple/input.go")
+	if err != nil {
+		t.Fatalf("failed to get absolute path: %v", err)
+	}
+	golden, err := filepath.Abs("../../testdata/simple/golden.go")
+	if err != nil {
+		t.Fatalf("failed to get absolute path: %v", err)
+	}
+	dir := setupModule(t, map[string]string{"a/fixtures.go": input, "c/done.go": golden})
+	pathA, pathC := filepath.Join(dir, "a", "fixtures.go"), filepath.Join(dir, "c", "done.go")
+
+	var stdout bytes.Buffer
+	opts := &runOptions{pattern: "./...", json: true, stdout: &stdout}
+	if err := run(t.Context(), opts, &fillstruct.Option{}); err != nil {
+		t.Fatalf("run returned unexpected error: %v", err)
+	}
+
+	var reports []fileReport
+	dec := json.NewDecoder(&stdout)
+	for dec.More() {
+		var report fileReport
+		if err := dec.Decode(&report); err != nil {
+			t.Fatalf("failed to decode report: %v", err)
+		}
+		reports = append(reports, report)
+	}
+	if len(reports) != 2 {
+		t.Fatalf("got %d reports, want 2", len(reports))
+	}
+	if got := reports[0]; got.Path != pathA || !got.Changed || len(got.Literals) != 1 {
+		t.Fatalf("unexpected report for %s: %+v", pathA, got)
+	}
+	lit := reports[0].Literals[0]
+	if !strings.HasSuffix(lit.Type, ".Person") || lit.Position.Line != 10 {
+		t.Errorf("unexpected literal report: %+v", lit)
+	}
+	if diff := cmp.Diff([]string{"Age"}, lit.Added); diff != "" {
+		t.Errorf("added fields mismatch (-want +got):\n%s", diff)
+	}
+	if got := reports[1]; got.Path != pathC || got.Changed || len(got.Literals) != 0 {
+		t.Errorf("unexpected report for %s: %+v", pathC, got)
+	}
+
+	original, err := os.ReadFile(input)
+	if err != nil {
+		t.Fatalf("failed to read input: %v", err)
+	}
+	if content, err := os.ReadFile(pathA); err != nil || !bytes.Equal(content, original) {
+		t.Errorf("%s was modified without -w", pathA)
+	}
+
+	// With -w, the changed files are written as well
+	opts.write = true
+	if err := run(t.Context(), opts, &fillstruct.Option{}); err != nil {
+		t.Fatalf("run returned unexpected error: %v", err)
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("failed to read golden: %v", err)
+	}
+	if content, err := os.ReadFile(pathA); err != nil || !bytes.Equal(content, want) {
+		t.Errorf("%s was not written with -w", pathA)
+	}
+}
+
+func TestRun_ErrorCount(t *testing.T) {
+	input, err := filepath.Abs("../../testdata/simple/input.go")
+	if err != nil {
+		t.Fatalf("failed to get absolute path: %v", err)
+	}
+	files := make(map[string]string)
+	for i := range 16 {
+		files[fmt.Sprintf("p%d/fixtures.go", i)] = input
+	}
+	setupModule(t, files)
+
+	// Every file reports two errors, from as many workers as there are CPUs
+	formatFile = func(ctx context.Context, pkg *packages.Package, file *ast.File, option *fillstruct.Option) (*fillstruct.FormatResult, error) {
+		pos := pkg.Fset.Position(file.Pos())
+		return &fillstruct.FormatResult{
+			Path: pos.Filename,
+			Errors: []*fillstruct.FormatError{
+				{Position: pos, Message: "first"},
+				{Position: pos, Message: "second"},
+			},
+		}, nil
+	}
+	t.Cleanup(func() { formatFile = fillstruct.FormatContext })
+
+	err = run(t.Context(), &runOptions{pattern: "./..."}, &fillstruct.Option{})
+	if err == nil || err.Error() != "failed to format 16 files" {
+		t.Errorf("run returned %v, want the count of files with errors", err)
+	}
+}
+
+func TestRun_FormatFailure(t *testing.T) {
+	input, err := filepath.Abs("../../testdata/simple/input.go")
+	if err != nil {
+		t.Fatalf("failed to get absolute path: %v", err)
+	}
+	golden, err := os.ReadFile("../../testdata/simple/golden.go")
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+	dir := setupModule(t, map[string]string{"a/fixtures.go": input, "b/fixtures.go": input})
+
+	// Formatting fails for one file only
+	failing := filepath.Join(dir, "a", "fixtures.go")
+	formatFile = func(ctx context.Context, pkg *packages.Package, file *ast.File, option *fillstruct.Option) (*fillstruct.FormatResult, error) {
+		if pkg.Fset.Position(file.Pos()).Filename == failing {
+			return nil, errors.New("broken file")
+		}
+		return fillstruct.FormatContext(ctx, pkg, file, option)
+	}
+	t.Cleanup(func() { formatFile = fillstruct.FormatContext })
+
+	err = run(t.Context(), &runOptions{pattern: "./..."}, &fillstruct.Option{})
+	if err == nil || !strings.Contains(err.Error(), "broken file") {
+		t.Errorf("run returned %v, want the format failure", err)
+	}
+
+	// The other file is still filled
+	got, err := os.ReadFile(filepath.Join(dir, "b", "fixtures.go"))
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	if diff := cmp.Diff(string(golden), string(got)); diff != "" {
+		t.Errorf("output mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFillStdin(t *testing.T) {
+	input, err := os.ReadFile("../../testdata/simple/input.go")
+	if err != nil {
+		t.Fatalf("failed to read input: %v", err)
+	}
+	golden, err := os.ReadFile("../../testdata/simple/golden.go")
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+	// The file on disk is shadowed by the source from stdin
+	setupModule(t, map[string]string{"fixtures.go": "../../testdata/simple/golden.go"})
+
+	var stdout bytes.Buffer
+	if err := fillStdin(t.Context(), bytes.NewReader(input), &stdout, "fixtures.go", &fillstruct.Option{}, "", false); err != nil {
+		t.Fatalf("fillStdin returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(string(golden), stdout.String()); diff != "" {
+		t.Errorf("output mismatch (-want +got):\n%s", diff)
+	}
+
+	// A complete source is written unchanged
+	stdout.Reset()
+	if err := fillStdin(t.Context(), bytes.NewReader(golden), &stdout, "fixtures.go", &fillstruct.Option{}, "", false); err != nil {
+		t.Fatalf("fillStdin returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(string(golden), stdout.String()); diff != "" {
+		t.Errorf("output mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestUnmatchedTargets(t *testing.T) {
+	app := types.NewPackage("example.com/app", "app")
+	named := func(name string) *types.Named {
+		return types.NewNamed(types.NewTypeName(token.NoPos, app, name, nil), types.NewStruct(nil, nil), nil)
+	}
+	option := &fillstruct.Option{
+		TargetTypes:     []*types.Named{named("Widget"), named("Gadget")},
+		TargetTypeNames: []string{"User", "Usr"},
+	}
+	matched := map[string]bool{"example.com/app.Gadget": true, "User": true}
+
+	got := unmatchedTargets(option, matched, nil)
+	if diff := cmp.Diff([]string{"example.com/app.Widget", "Usr"}, got); diff != "" {
+		t.Errorf("unmatched targets mismatch (-want +got):\n%s", diff)
+	}
+
+	// Types of packages given with a wildcard are not reported
+	got = unmatchedTargets(option, matched, wildcardPackages([]string{"example.com/app.*"}))
+	if diff := cmp.Diff([]string{"Usr"}, got); diff != "" {
+		t.Errorf("unmatched targets mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRun_Parallel(t *testing.T) {
+	input, err := filepath.Abs("../../testdata/simple/input.go")
+	if err != nil {
+		t.Fatalf("failed to get absolute path: %v", err)
+	}
+	files := make(map[string]string)
+	for i := range 6 {
+		files[fmt.Sprintf("p%d/fixtures.go", i)] = input
+	}
+	setupModule(t, files)
+
+	// Record the highest number of files formatted at the same time
+	var running, peak atomic.Int32
+	formatFile = func(ctx context.Context, pkg *packages.Package, file *ast.File, option *fillstruct.Option) (*fillstruct.FormatResult, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		return fillstruct.FormatContext(ctx, pkg, file, option)
+	}
+	t.Cleanup(func() { formatFile = fillstruct.FormatContext })
+
+	if err := run(t.Context(), &runOptions{pattern: "./...", parallel: 2}, &fillstruct.Option{}); err != nil {
+		t.Fatalf("run returned unexpected error: %v", err)
+	}
+	if got := peak.Load(); got > 2 {
+		t.Errorf("%d files were formatted at the same time, want at most 2", got)
+	}
+}
+
+func TestRun_Verbose(t *testing.T) {
+	input, err := filepath.Abs("../../testdata/simple/input.go")
+	if err != nil {
+		t.Fatalf("failed to get absolute path: %v", err)
+	}
+	dir := setupModule(t, map[string]string{"fixtures.go": input})
+
+	// Traces are written to stderr
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+	stderr := os.Stderr
+	os.Stderr = w
+	t.Cleanup(func() { os.Stderr = stderr })
+
+	err = run(t.Context(), &runOptions{pattern: "./...", verbose: true}, &fillstruct.Option{})
+	w.Close()
+	os.Stderr = stderr
+	if err != nil {
+		t.Fatalf("run returned unexpected error: %v", err)
+	}
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("failed to read stderr: %v", err)
+	}
+
+	want := filepath.Join(dir, "fixtures.go") + ":10:7: Person literal filled: Age\n"
+	if diff := cmp.Diff(want, string(out)); diff != "" {
+		t.Errorf("stderr mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRun_ErrorsJSON(t *testing.T) {
+	input, err := filepath.Abs("../../testdata/unknown_placeholder/input.go")
+	if err != nil {
+		t.Fatalf("failed to get absolute path: %v", err)
+	}
+	dir := setupModule(t, map[string]string{"fixtures.go": input})
+	path := filepath.Join(dir, "fixtures.go")
+
+	// Diagnostics are written to stderr
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+	stderr := os.Stderr
+	os.Stderr = w
+	t.Cleanup(func() { os.Stderr = stderr })
+
+	option := &fillstruct.Option{UnknownPlaceholder: "FILLSTRUCT_TODO", TargetTypeNames: []string{"Box", "Missing"}}
+	err = run(t.Context(), &runOptions{pattern: "./...", errorsJSON: true}, option)
+	w.Close()
+	os.Stderr = stderr
+	if err != nil {
+		t.Fatalf("run returned unexpected error: %v", err)
+	}
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("failed to read stderr: %v", err)
+	}
+
+	// The placeholder is reported for the file, the unknown type for the run
+	want := `{"file":"` + path + `","message":"field Value has unsupported type T, filled with placeholder FILLSTRUCT_TODO","position":{"filename":"` + path + `","offset":118,"line":9,"column":9},"severity":"warning"}` + "\n" +
+		`{"file":"","message":"target type Missing matched no literals","position":{"filename":"","offset":0,"line":0,"column":0},"severity":"warning"}` + "\n"
+	if diff := cmp.Diff(want, string(out)); diff != "" {
+		t.Errorf("stderr mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/nametake/fillstruct"
+	"golang.org/x/tools/go/packages"
+)
+
+// formatSource fills text as the content of the file at path, which does not need to
+// exist. The package of the file is loaded with text as an overlay, so the file on disk,
+// if any, is ignored.
+func formatSource(ctx context.Context, path string, text []byte, option *fillstruct.Option, tags string) (*fillstruct.FormatResult, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %v", path, err)
+	}
+
+	cfg := &packages.Config{
+		Mode:    packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo | packages.NeedFiles | packages.NeedImports | packages.NeedDeps,
+		Context: ctx,
+		Dir:     filepath.Dir(path),
+		Tests:   true,
+		Overlay: map[string][]byte{path: text},
+	}
+	if tags != "" {
+		cfg.BuildFlags = []string{"-tags=" + tags}
+	}
+	pkgs, err := packages.Load(cfg, "file="+path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages: path = %s: %v", path, err)
+	}
+
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Syntax {
+			if pkg.Fset.Position(file.Pos()).Filename != path {
+				continue
+			}
+			return fillstruct.FormatContext(ctx, pkg, file, option)
+		}
+	}
+	return nil, fmt.Errorf("no package found for %s", path)
+}
+
+// fillStdin fills the source read from stdin as the file at path and writes the result
+// to stdout. The source is written unchanged when there is nothing to fill or it fails.
+func fillStdin(ctx context.Context, stdin io.Reader, stdout io.Writer, path string, option *fillstruct.Option, tags string, errorsJSON bool) error {
+	text, err := io.ReadAll(stdin)
+	if err != nil {
+		return fmt.Errorf("failed to read stdin: %v", err)
+	}
+
+	result, err := formatSource(ctx, path, text, option, tags)
+	if err != nil {
+		stdout.Write(text)
+		return err
+	}
+	for _, formatErr := range result.Errors {
+		printFormatError(os.Stderr, formatErr, severityError, errorsJSON)
+	}
+	for _, warning := range result.Warnings {
+		printFormatError(os.Stderr, warning, severityWarning, errorsJSON)
+	}
+
+	output := text
+	if result.Changed {
+		output = result.Output
+	}
+	if _, err := stdout.Write(output); err != nil {
+		return fmt.Errorf("failed to write stdout: %v", err)
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("failed to format %s", path)
+	}
+	return nil
+}
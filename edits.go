@@ -0,0 +1,220 @@
+package fillstruct
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"slices"
+	"strings"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+)
+
+// TextEdit replaces the bytes of the input between the offsets Start and End with NewText
+type TextEdit struct {
+	Start   int
+	End     int
+	NewText string
+}
+
+// TextEdits returns the edits filling the literals of the input and adding imports,
+// sorted by offset. Unlike Output, they leave the rest of the file as written.
+func (r *FormatResult) TextEdits() []TextEdit {
+	edits := slices.Clone(r.ImportEdits)
+	for _, lit := range r.Literals {
+		edits = append(edits, lit.Edits...)
+	}
+	slices.SortStableFunc(edits, func(a, b TextEdit) int {
+		return a.Start - b.Start
+	})
+	return edits
+}
+
+// literalFill records how a literal of the input was filled
+type literalFill struct {
+	lit    *ast.CompositeLit
+	report *LiteralReport
+	elts   []dst.Expr            // elements of the filled literal, in order
+	input  map[dst.Expr]ast.Expr // elements that were in the input
+	keys   []string              // fields given to the positional elements, nil when keyed
+}
+
+// elementText is an added element as printed
+type elementText struct {
+	inline string   // the element alone, for literals on a single line
+	lines  []string // the lines of the element with its comma and comment, indented relative to it
+}
+
+// setLiteralEdits sets the edits of the reports of the filled literals. The elements
+// added to a literal are inserted after the element preceding them in the literal, so
+// that the literal and the rest of the file are not reformatted.
+func setLiteralEdits(tokFile *token.File, fills []*literalFill) error {
+	texts, err := printAddedElements(fills)
+	if err != nil {
+		return err
+	}
+
+	line := func(pos token.Pos) int {
+		return tokFile.PositionFor(pos, false).Line
+	}
+	insert := func(offset int, text string) TextEdit {
+		return TextEdit{Start: offset, End: offset, NewText: text}
+	}
+
+	for _, fill := range fills {
+		lit := fill.lit
+		var edits []TextEdit
+
+		// Literals with the closing brace on its own line get an element per line,
+		// indented one level more than the brace
+		last := lit.Lbrace
+		if len(lit.Elts) > 0 {
+			last = lit.Elts[len(lit.Elts)-1].End()
+		}
+		multiLine := line(lit.Rbrace) > line(last)
+		indent := strings.Repeat("\t", tokFile.PositionFor(lit.Rbrace, false).Column)
+
+		for i, key := range fill.keys {
+			edits = append(edits, insert(tokFile.Offset(lit.Elts[i].Pos()), key+": "))
+		}
+
+		// Added elements are grouped by the input element they follow
+		var anchor ast.Expr
+		var added []elementText
+		flush := func(next ast.Expr) {
+			if len(added) == 0 {
+				return
+			}
+			switch {
+			case multiLine:
+				after := lit.Lbrace
+				if anchor != nil {
+					after = anchor.End()
+				}
+				var text strings.Builder
+				for _, elt := range added {
+					for _, l := range elt.lines {
+						if l != "\n" {
+							text.WriteString(indent)
+						}
+						text.WriteString(l)
+					}
+				}
+				edits = append(edits, insert(tokFile.Offset(tokFile.LineStart(line(after)+1)), text.String()))
+			case anchor != nil:
+				var text strings.Builder
+				for _, elt := range added {
+					text.WriteString(", " + elt.inline)
+				}
+				edits = append(edits, insert(tokFile.Offset(anchor.End()), text.String()))
+			default:
+				var inline []string
+				for _, elt := range added {
+					inline = append(inline, elt.inline)
+				}
+				if next != nil {
+					edits = append(edits, insert(tokFile.Offset(next.Pos()), strings.Join(inline, ", ")+", "))
+				} else {
+					edits = append(edits, insert(tokFile.Offset(lit.Rbrace), strings.Join(inline, ", ")))
+				}
+			}
+			added = nil
+		}
+		for _, elt := range fill.elts {
+			if input, ok := fill.input[elt]; ok {
+				flush(input)
+				anchor = input
+				continue
+			}
+			added = append(added, texts[elt])
+		}
+		flush(nil)
+
+		slices.SortStableFunc(edits, func(a, b TextEdit) int {
+			return a.Start - b.Start
+		})
+		fill.report.Edits = edits
+	}
+	return nil
+}
+
+// printAddedElements prints the elements added to the literals, all at once in a
+// formatted file holding a literal per filled literal
+func printAddedElements(fills []*literalFill) (map[dst.Expr]elementText, error) {
+	var groups []dst.Expr
+	var added [][]dst.Expr
+	for _, fill := range fills {
+		group := &dst.CompositeLit{}
+		group.Decs.Before = dst.NewLine
+		group.Decs.After = dst.NewLine
+		var elts []dst.Expr
+		for _, elt := range fill.elts {
+			if _, ok := fill.input[elt]; ok {
+				continue
+			}
+			clone := dst.Clone(elt).(dst.Expr)
+			clone.Decorations().Before = dst.NewLine
+			clone.Decorations().After = dst.NewLine
+			group.Elts = append(group.Elts, clone)
+			elts = append(elts, elt)
+		}
+		groups = append(groups, group)
+		added = append(added, elts)
+	}
+
+	file := &dst.File{
+		Name: dst.NewIdent("p"),
+		Decls: []dst.Decl{&dst.GenDecl{
+			Tok: token.VAR,
+			Specs: []dst.Spec{&dst.ValueSpec{
+				Names:  []*dst.Ident{dst.NewIdent("_")},
+				Values: []dst.Expr{&dst.CompositeLit{Type: &dst.ArrayType{Elt: dst.NewIdent("any")}, Elts: groups}},
+			}},
+		}},
+	}
+	var buf bytes.Buffer
+	if err := decorator.Fprint(&buf, file); err != nil {
+		return nil, fmt.Errorf("failed to print added fields: %w", err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format added fields: %w", err)
+	}
+	fset := token.NewFileSet()
+	printed, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse added fields: %w", err)
+	}
+	tokFile := fset.File(printed.Pos())
+
+	texts := make(map[dst.Expr]elementText)
+	values := printed.Decls[0].(*ast.GenDecl).Specs[0].(*ast.ValueSpec).Values[0].(*ast.CompositeLit)
+	for i, group := range values.Elts {
+		for j, elt := range group.(*ast.CompositeLit).Elts {
+			start := tokFile.Offset(tokFile.LineStart(tokFile.Line(elt.Pos())))
+			end := tokFile.Offset(elt.End())
+			end += bytes.IndexByte(src[end:], '\n') + 1
+
+			// Elements are printed two levels deep
+			var lines []string
+			for _, l := range strings.SplitAfter(string(src[start:end]), "\n") {
+				if l != "" {
+					lines = append(lines, strings.TrimPrefix(l, "\t\t"))
+				}
+			}
+
+			// Without the alignment of the values of the printed literal
+			kv := elt.(*ast.KeyValueExpr)
+			value := src[tokFile.Offset(kv.Value.Pos()):tokFile.Offset(kv.Value.End())]
+			texts[added[i][j]] = elementText{
+				inline: kv.Key.(*ast.Ident).Name + ": " + string(value),
+				lines:  lines,
+			}
+		}
+	}
+	return texts, nil
+}
@@ -0,0 +1,62 @@
+package fillstruct
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFormatResult_TextEdits(t *testing.T) {
+	src, err := os.ReadFile("testdata/literal_edits/input.go")
+	if err != nil {
+		t.Fatalf("failed to read input file: %v", err)
+	}
+	pkg := loadTestPackage(t, "testdata", "literal_edits/input.go")
+
+	tests := []struct {
+		name       string
+		option     *Option
+		goldenFile string
+	}{
+		{
+			name:       "fields are inserted without reformatting the file",
+			option:     &Option{},
+			goldenFile: "testdata/literal_edits/golden_edits.go",
+		},
+		{
+			name:       "positional elements are keyed in place",
+			option:     &Option{ConvertPositional: true},
+			goldenFile: "testdata/literal_edits/golden_positional.go",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			want, err := os.ReadFile(test.goldenFile)
+			if err != nil {
+				t.Fatalf("failed to read golden file: %v", err)
+			}
+			got, err := Format(pkg, pkg.Syntax[0], test.option)
+			if err != nil {
+				t.Fatalf("Format returned unexpected error: %v", err)
+			}
+
+			// Only the literals and the imports are edited; the unformatted declaration is kept
+			edits := got.TextEdits()
+			applied := string(src)
+			for i := len(edits) - 1; i >= 0; i-- {
+				applied = applied[:edits[i].Start] + edits[i].NewText + applied[edits[i].End:]
+			}
+			if diff := cmp.Diff(string(want), applied); diff != "" {
+				t.Errorf("applying the edits gives unexpected source (-want +got):\n%s", diff)
+			}
+
+			for _, lit := range got.Literals {
+				if len(lit.Missing) > 0 && len(lit.Edits) == 0 {
+					t.Errorf("literal at %v has no edits", lit.Position)
+				}
+			}
+		})
+	}
+}
@@ -2,42 +2,255 @@ package fillstruct
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"go/ast"
+	"go/constant"
 	"go/format"
+	"go/parser"
 	"go/token"
 	"go/types"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"unicode"
 	"unicode/utf8"
 
 	"github.com/dave/dst"
 	"github.com/dave/dst/decorator"
+	"github.com/nametake/fillstruct/internal/deps"
 	"golang.org/x/tools/go/packages"
 )
 
 type FormatError struct {
-	Message string
-	PosText string
+	Message  string
+	PosText  string
+	Position token.Position
+}
+
+func newFormatError(position token.Position, message string) *FormatError {
+	return &FormatError{
+		Message:  message,
+		PosText:  position.String(),
+		Position: position,
+	}
 }
 
 func (e *FormatError) String() string {
 	return fmt.Sprintf("%s:\n%s", e.PosText, e.Message)
 }
 
+// jsonPosition is token.Position with keys in the case of the other keys of FormatError
+type jsonPosition struct {
+	Filename string `json:"filename"`
+	Offset   int    `json:"offset"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+}
+
+// MarshalJSON encodes the error in a machine-readable form for editor integrations
+func (e *FormatError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		File     string       `json:"file"`
+		Position jsonPosition `json:"position"`
+		Message  string       `json:"message"`
+	}{
+		File:     e.Position.Filename,
+		Position: jsonPosition(e.Position),
+		Message:  e.Message,
+	})
+}
+
 type FormatResult struct {
-	Path    string
-	Output  []byte
-	Errors  []*FormatError
-	Changed bool
+	Path     string
+	Output   []byte
+	Errors   []*FormatError
+	Warnings []*FormatError // non-fatal findings, nil when there are none
+	Changed  bool
+
+	// Literals lists the keyed literals of matching types, complete or not, in source order
+	Literals []*LiteralReport
+
+	// ImportEdits adds the imports of generated values to the input, see TextEdits
+	ImportEdits []TextEdit
+
+	// MatchedTargets lists the target types with at least one literal in the file, including
+	// struct values added for missing fields, as types.TypeString of Option.TargetTypes or
+	// the names of Option.TargetTypeNames, sorted
+	MatchedTargets []string
+}
+
+// LiteralReport describes a literal considered for filling
+type LiteralReport struct {
+	Type     string         // e.g., "github.com/example/foo.User", or the struct type for anonymous structs
+	Position token.Position // start of the literal in the input, where missing fields are inserted
+	Missing  []string       // fields that were missing and filled, nil when the literal was complete
+	Edits    []TextEdit     // insertions filling the literal in the input, nil when it was complete
 }
 
 type Option struct {
-	TargetTypes    []*types.Named
-	CustomDefaults map[string]string // "importpath.TypeName" -> "ConstantName"
+	TargetTypes      []*types.Named
+	ExcludeTypes     []*types.Named    // never filled, even when also targeted
+	CustomDefaults   map[string]string // "importpath.TypeName" -> "ConstantName"
+	SkipAnonymous    bool              // skip anonymous struct literals, which are filled by default
+	IncludeAnonymous bool              // also fill anonymous struct literals when target types are given
+	TargetTypeNames  []string          // bare type names (e.g., "User") matched against the package being formatted
+	TopLevelOnly     bool              // only fill literals in top-level declarations, skipping function bodies
+	FieldOrder       FieldOrder        // placement of added fields (default: StructOrder)
+	PreserveOrder    bool              // keep existing fields in place and append missing ones; same as FieldOrder AppendSorted
+
+	// NormalizePaths compares the package paths of target and excluded types after
+	// normalizing them, so that targeting works with vendored and replaced copies of a
+	// module: everything up to a "vendor" element is removed (e.g.,
+	// "example.com/app/vendor/example.com/lib" is "example.com/lib") and module paths
+	// are mapped by ModuleReplacements. Copies still have to declare the same fields.
+	NormalizePaths bool
+
+	// ModuleReplacements maps module paths to the module path they replace, as replace
+	// directives with another module do (e.g., "example.com/fork/lib" -> "example.com/lib").
+	// It is only used with NormalizePaths.
+	ModuleReplacements map[string]string
+
+	// MatchUnnamedByShape limits filling of anonymous struct literals to the given shapes,
+	// compared with types.Identical. All anonymous structs are filled when it is empty.
+	MatchUnnamedByShape []*types.Struct
+
+	// ExcludeFieldRegexp excludes fields whose name matches it (e.g., "^XXX_" for protobuf internals)
+	ExcludeFieldRegexp *regexp.Regexp
+
+	// FieldInclude limits the added fields to those whose name matches one of the glob
+	// patterns (e.g., "*ID"), as in path.Match; all fields are added when it is empty.
+	// FieldExclude leaves out fields whose name matches one of its patterns. Fields left
+	// out are simply not added.
+	FieldInclude []string
+	FieldExclude []string
+
+	// IncludeGenerated fills generated files entirely. By default generated files are
+	// skipped except for regions enclosed by //fillstruct:begin and //fillstruct:end.
+	IncludeGenerated bool
+
+	// SkipFinalFormat returns the printed output without running it through gofmt,
+	// e.g., to apply another formatter. The output may not be gofmt-clean.
+	SkipFinalFormat bool
+
+	// UnknownPlaceholder is an expression used instead of nil for fields whose type is not
+	// supported (e.g., type parameters). Each use is reported in FormatResult.Warnings.
+	UnknownPlaceholder string
+
+	// Position restricts filling to the innermost struct literal enclosing the position
+	// (Line and Column, 1-based), e.g., the cursor of an editor. All literals are filled
+	// when Line is zero.
+	Position token.Position
+
+	// TypeGenerators generates the value of fields by their type, keyed by the type string
+	// (e.g., "example.com/money.Amount" or "*example.com/money.Amount"). A generator returns
+	// the expression and the import paths it references, which are added to the file.
+	// It takes precedence over all other defaults; a nil expression falls back to them.
+	TypeGenerators map[string]func(t types.Type, pkg *packages.Package) (dst.Expr, []string)
+
+	// InterfaceDefaults maps named interface types ("importpath.InterfaceName") to the
+	// expression filled for them instead of nil (e.g., "io.Writer" -> "io.Discard").
+	// Packages referenced by the expression are imported when they are dependencies of
+	// the package being formatted. Expressions that do not parse are ignored.
+	InterfaceDefaults map[string]string
+
+	// SourceRefComments appends a comment with the file and line declaring the field to
+	// each added field (e.g., "// field defined at example.com/models/user.go:12").
+	SourceRefComments bool
+
+	// FillZeroForNamedBasic uses the literal zero (e.g., 0 or "") for named basic types
+	// instead of the constant holding their zero value (e.g., StatusUnknown)
+	FillZeroForNamedBasic bool
+
+	// TypedNil converts nil to the field type for pointer, slice, map, channel, function
+	// and interface fields (e.g., (*Foo)(nil) instead of nil), as some generic code requires.
+	TypedNil bool
+
+	// StubFuncs fills function fields with a function literal matching the signature that
+	// returns the zero value of each result (e.g., func(ctx context.Context) error { return nil })
+	// instead of nil, e.g., for mocks
+	StubFuncs bool
+
+	// NonNilPointers fills fields of pointer to named struct types with a pointer to an
+	// empty value (e.g., &Address{}) instead of nil. Other pointers stay nil, and so do
+	// pointers to the type of a value added for a missing field inside that value.
+	NonNilPointers bool
+
+	// Struct values added for missing fields are filled in the same pass when literals of
+	// their type are filled, so that formatting the output again changes nothing.
+	// Recursive fills the fields of all added struct values, recursively, instead of leaving
+	// the others empty (e.g., Server: Server{Host: "", Port: 0} instead of Server: Server{}).
+	// RecursiveDepth limits the number of nested levels filled this way; zero means no limit.
+	// Self-referential types are left empty when reached again. The fields get the values
+	// missing fields of literals get, including the defaults of tags and doc comments.
+	Recursive      bool
+	RecursiveDepth int
+
+	// EmptyNamedCollections uses empty composite literals (e.g., Tags{}) for named slice and
+	// map types instead of nil
+	EmptyNamedCollections bool
+
+	// DocCommentDefaults seeds missing fields from a "Default:" line in the doc comment of the
+	// struct type (e.g., "// Default: Timeout=30, Retries=3"). Values are used verbatim and
+	// entries that do not parse are ignored. Only types declared in loaded syntax are read.
+	DocCommentDefaults bool
+
+	// TypesInfoOverride is used instead of pkg.TypesInfo to look up the types of literals,
+	// e.g., by linters that already type checked the file. It must record Types for the file.
+	TypesInfoOverride *types.Info
+
+	// ConvertPositional converts incomplete positional literals (e.g., Person{"alice"}) to
+	// keyed form, matching the elements to the fields in order, and fills them. Complete
+	// positional literals and literals mixing keyed and positional elements are left unchanged.
+	ConvertPositional bool
+
+	// SampleValues fills plausible sample values instead of zero values, e.g., for test
+	// fixtures. SampleValue decides the value of each field; when it is nil, values are
+	// guessed from field names and types (e.g., "user@example.com" for Email, 1 for ID and
+	// time.Now() for time.Time). Fields without a sample value get their zero value.
+	// Expressions returned by SampleValue are used verbatim, so the packages they reference
+	// must be imported by the file.
+	SampleValues bool
+	SampleValue  func(field *types.Var) (dst.Expr, bool)
+
+	// IncludeUnexported also fills unexported fields of structs declared in the package of
+	// the literal. Unexported fields of structs from other packages are never filled.
+	IncludeUnexported bool
+
+	// TagKey is the struct tag key read for field directives: "-" skips the field and
+	// "default=Name" sets its default (e.g., `fillstruct:"-"`). Defaults to "fillstruct".
+	TagKey string
+
+	// FieldFilter reports whether a field of the struct may be filled. named is nil
+	// for anonymous structs. Fields for which it returns false are left missing.
+	FieldFilter func(field *types.Var, named *types.Named) bool
+
+	// Trace is called with a message for each struct literal considered, telling whether it
+	// was skipped and why or which fields were added, e.g., to debug why a literal is left
+	// unchanged. It is called from the goroutine running Format.
+	Trace func(pos token.Position, message string)
 }
 
+// FieldOrder controls where missing fields are placed in a filled literal
+type FieldOrder int
+
+const (
+	// StructOrder rebuilds the literal in struct declaration order, moving existing fields as needed
+	StructOrder FieldOrder = iota
+	// AppendSorted keeps existing fields where the author put them and appends the missing
+	// fields after them, in struct declaration order relative to each other
+	AppendSorted
+)
+
 // ResolveTargetTypes resolves type specifications to *types.Named
-// typeSpecs format: "importpath.TypeName" (e.g., "github.com/example/foo.Bar")
+// typeSpecs format: "importpath.TypeName" (e.g., "github.com/example/foo.Bar"), or
+// "importpath.*" for all named struct types declared in the package
 // dir is the directory to resolve packages from (e.g., "." or "./...")
 func ResolveTargetTypes(typeSpecs []string, dir string) ([]*types.Named, error) {
 	if len(typeSpecs) == 0 {
@@ -65,7 +278,7 @@ func ResolveTargetTypes(typeSpecs []string, dir string) ([]*types.Named, error)
 
 		// Load the package
 		cfg := &packages.Config{
-			Mode:  packages.NeedTypes | packages.NeedTypesInfo | packages.NeedImports,
+			Mode:  packages.NeedTypes | packages.NeedTypesInfo | packages.NeedImports | packages.NeedDeps,
 			Dir:   dir,
 			Tests: true,
 		}
@@ -78,6 +291,16 @@ func ResolveTargetTypes(typeSpecs []string, dir string) ([]*types.Named, error)
 			return nil, fmt.Errorf("no packages found for %q", importPath)
 		}
 
+		// A "*" type name matches all named struct types declared in the package
+		if typeName == "*" {
+			named, err := packageStructTypes(pkgs, importPath)
+			if err != nil {
+				return nil, err
+			}
+			targetTypes = append(targetTypes, named...)
+			continue
+		}
+
 		// Try to find the type in all loaded packages (including test packages)
 		var obj types.Object
 		var foundPkg *packages.Package
@@ -122,50 +345,205 @@ func ResolveTargetTypes(typeSpecs []string, dir string) ([]*types.Named, error)
 }
 
 func Format(pkg *packages.Package, file *ast.File, option *Option) (*FormatResult, error) {
-	path := pkg.Fset.Position(file.Pos()).Filename
+	return FormatContext(context.Background(), pkg, file, option)
+}
+
+// FormatFile is like Format for callers that parse and type check files themselves
+// instead of loading them with go/packages. info must hold the types recorded by
+// types.Config.Check for the package of the file.
+func FormatFile(fset *token.FileSet, file *ast.File, info *types.Info, option *Option) (*FormatResult, error) {
+	pkg := &packages.Package{
+		Fset:      fset,
+		Syntax:    []*ast.File{file},
+		Types:     filePackage(file, info),
+		TypesInfo: info,
+	}
+	pkg.PkgPath = pkg.Types.Path()
+	pkg.Name = pkg.Types.Name()
+	return FormatContext(context.Background(), pkg, file, option)
+}
+
+// filePackage returns the package declaring the objects of the file, or a new package
+// named after the package clause when the file declares nothing
+func filePackage(file *ast.File, info *types.Info) *types.Package {
+	for ident, obj := range info.Defs {
+		if obj != nil && obj.Pkg() != nil && ident.Pos() >= file.Pos() && ident.Pos() < file.End() {
+			return obj.Pkg()
+		}
+	}
+	return types.NewPackage(file.Name.Name, file.Name.Name)
+}
+
+// FormatContext is like Format but stops early and returns the context error
+// when ctx is canceled
+func FormatContext(ctx context.Context, pkg *packages.Package, file *ast.File, option *Option) (*FormatResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// The file itself rather than the one named by a //line directive
+	path := pkg.Fset.PositionFor(file.Pos(), false).Filename
 	errors := make([]*FormatError, 0)
 
+	// Files translated by cgo are not the source on disk; rewriting them would
+	// replace the original file, including its preamble, with cgo's output
+	if cgoFile(pkg, file) {
+		return &FormatResult{
+			Path:   pkg.Fset.Position(file.Pos()).Filename,
+			Output: nil,
+			Errors: errors,
+			Warnings: []*FormatError{
+				newFormatError(pkg.Fset.Position(file.Pos()), `skipping file translated by cgo: literals in files importing "C" are not filled`),
+			},
+			Changed: false,
+		}, nil
+	}
+
+	info := pkg.TypesInfo
+	if option.TypesInfoOverride != nil {
+		info = option.TypesInfoOverride
+	}
+
+	trace := func(pos token.Pos, format string, args ...any) {
+		if option.Trace != nil {
+			option.Trace(pkg.Fset.Position(pos), fmt.Sprintf(format, args...))
+		}
+	}
+
+	// Decorating is by far the most expensive step, so files without any literal
+	// that could be filled are returned unchanged before paying for it. Traced runs
+	// decorate anyway to explain why each literal is skipped.
+	if option.Trace == nil && !hasCandidateLiteral(file, info, pkg, option) {
+		return &FormatResult{
+			Path:    path,
+			Output:  nil,
+			Errors:  errors,
+			Changed: false,
+		}, nil
+	}
+
 	// Convert ast.File to dst.File
 	dec := decorator.NewDecorator(pkg.Fset)
-	dstFile, err := dec.DecorateFile(file)
+	dstFile, err := decorateFile(dec, file)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decorate file: %w", err)
+		// Record the failure and let callers continue with other files
+		errors = append(errors, newFormatError(
+			pkg.Fset.Position(file.Pos()),
+			fmt.Sprintf("failed to decorate file: %v", err),
+		))
+		return &FormatResult{
+			Path:    path,
+			Output:  nil,
+			Errors:  errors,
+			Changed: false,
+		}, nil
+	}
+
+	// Generated files are skipped unless they mark regions to fill
+	var regions []fillRegion
+	if ast.IsGenerated(file) && !option.IncludeGenerated {
+		regions = findFillRegions(file)
+		if len(regions) == 0 {
+			trace(file.Pos(), "generated file skipped: no //fillstruct:begin regions")
+			return &FormatResult{
+				Path:    path,
+				Output:  nil,
+				Errors:  errors,
+				Changed: false,
+			}, nil
+		}
 	}
 
 	changed := false
+	var warnings []*FormatError
+	var literals []*LiteralReport
+	var fills []*literalFill
+	matched := make(map[string]bool)
+	appendSorted := option.FieldOrder == AppendSorted || option.PreserveOrder
+
+	state := newFileState(file, info)
+	if option.UnknownPlaceholder != "" {
+		placeholder, err := parseExpr(option.UnknownPlaceholder)
+		if err != nil {
+			return nil, fmt.Errorf("invalid unknown placeholder %q: %w", option.UnknownPlaceholder, err)
+		}
+		state.placeholder = placeholder
+	}
+
+	var targetLit *ast.CompositeLit
+	if option.Position.Line > 0 {
+		pos, err := filePos(pkg.Fset.File(file.Pos()), option.Position)
+		if err != nil {
+			return nil, err
+		}
+		targetLit = structLiteralAt(info, file, pos)
+	}
 
 	// Inspect and modify composite literals
 	dst.Inspect(dstFile, func(n dst.Node) bool {
-		lit, ok := n.(*dst.CompositeLit)
-		if !ok {
-			return true
+		if ctx.Err() != nil {
+			return false
 		}
 
-		// Get corresponding ast.Node to access type information
-		astNode := dec.Ast.Nodes[lit]
-		astLit, ok := astNode.(*ast.CompositeLit)
-		if !ok {
-			return true
+		// Skip function bodies when only top-level declarations are considered
+		if option.TopLevelOnly {
+			switch n.(type) {
+			case *dst.FuncDecl, *dst.FuncLit:
+				return false
+			}
 		}
 
-		// Get type information
-		tv, ok := pkg.TypesInfo.Types[astLit]
+		lit, ok := n.(*dst.CompositeLit)
 		if !ok {
 			return true
 		}
 
+		// Struct values added for missing fields are filled as well, as a second run would
+		// fill them, except when only the literal at Option.Position is filled
+		var litType types.Type
+		var pos token.Pos
+		var astLit *ast.CompositeLit
+		gen, generated := state.generated[lit]
+		if generated {
+			if option.Position.Line > 0 {
+				return true
+			}
+			litType, pos = gen.typ, gen.pos
+		} else {
+			// Get corresponding ast.Node to access type information
+			astLit, ok = dec.Ast.Nodes[lit].(*ast.CompositeLit)
+			if !ok {
+				return true
+			}
+
+			if regions != nil && !inFillRegions(regions, astLit.Pos()) {
+				return true
+			}
+			if option.Position.Line > 0 && astLit != targetLit {
+				return true
+			}
+
+			// Get type information
+			tv, ok := info.Types[astLit]
+			if !ok {
+				return true
+			}
+			litType, pos = tv.Type, astLit.Pos()
+		}
+
 		// Get the underlying struct type and check if it matches target types
 		var structType *types.Struct
 		var namedType *types.Named
 
-		switch t := tv.Type.(type) {
+		// Literals of aliases are filled like literals of the type they denote
+		switch t := types.Unalias(litType).(type) {
 		case *types.Named:
 			if s, ok := t.Underlying().(*types.Struct); ok {
 				structType = s
 				namedType = t
 			}
 		case *types.Pointer:
-			if named, ok := t.Elem().(*types.Named); ok {
+			if named, ok := types.Unalias(t.Elem()).(*types.Named); ok {
 				if s, ok := named.Underlying().(*types.Struct); ok {
 					structType = s
 					namedType = named
@@ -178,32 +556,79 @@ func Format(pkg *packages.Package, file *ast.File, option *Option) (*FormatResul
 		if structType == nil {
 			return true
 		}
+		typeName := "anonymous struct"
+		if namedType != nil {
+			typeName = types.TypeString(namedType, types.RelativeTo(pkg.Types))
+		}
+
+		// Skip anonymous structs unless explicitly enabled
+		if namedType == nil && option.SkipAnonymous && !option.IncludeAnonymous {
+			trace(pos, "%s literal skipped: anonymous structs are not filled", typeName)
+			return true
+		}
+		if namedType == nil && len(option.MatchUnnamedByShape) > 0 && !matchesShape(structType, option.MatchUnnamedByShape) {
+			trace(pos, "%s literal skipped: no matching shape", typeName)
+			return true
+		}
+
+		// Excluded types are skipped even when they are also targeted
+		if namedType != nil && isExcludedType(namedType, option) {
+			trace(pos, "%s literal skipped: excluded type", typeName)
+			return true
+		}
 
 		// If target types are specified, check if this type matches
-		if len(option.TargetTypes) > 0 {
-			if namedType == nil {
+		targeted := len(option.TargetTypes) > 0 || len(option.TargetTypeNames) > 0
+		if targeted {
+			switch {
+			case namedType == nil && option.IncludeAnonymous:
+				// Anonymous structs bypass the match with the target types
+			case namedType == nil:
 				// Skip anonymous structs when target types are specified
+				trace(pos, "%s literal skipped: not a target type", typeName)
 				return true
-			}
-
-			matched := false
-			for _, targetType := range option.TargetTypes {
-				// Compare by package path and type name instead of types.Identical
-				// because they may be from different package loads
-				if namedType.Obj().Pkg().Path() == targetType.Obj().Pkg().Path() &&
-					namedType.Obj().Name() == targetType.Obj().Name() {
-					matched = true
-					break
+			default:
+				target, ok := matchTargetType(namedType, pkg, option)
+				if !ok {
+					trace(pos, "%s literal skipped: not a target type", typeName)
+					return true
 				}
+				// Added values count too, the type is filled in the file either way
+				matched[target] = true
 			}
+		}
 
-			if !matched {
+		// Fields that can be added, in struct field order
+		allFields := fillableFields(structType, namedType, pkg, option)
+
+		// Check if all elements are keyed. Positional literals are skipped unless they can be
+		// converted, which is only reported for explicitly targeted types to avoid noise when
+		// filling all. Only literals with fields to add are converted, so the others are left
+		// as written.
+		// Positional elements set the fields in order, so only the fields after them are missing
+		positionalMissing := false
+		for _, field := range allFields {
+			positionalMissing = positionalMissing || field.index >= len(lit.Elts)
+		}
+		var keys []string
+		if option.ConvertPositional && len(lit.Elts) > 0 && isAllPositional(lit.Elts) {
+			if !positionalMissing {
+				trace(pos, "%s literal is complete", typeName)
 				return true
 			}
+			keyPositional(lit.Elts, structType)
+			for _, elt := range lit.Elts {
+				keys = append(keys, elt.(*dst.KeyValueExpr).Key.(*dst.Ident).Name)
+			}
 		}
-
-		// Check if all elements are keyed
 		if !isAllKeyed(lit.Elts) {
+			trace(pos, "%s literal skipped: not all elements are keyed", typeName)
+			if targeted && namedType != nil && positionalMissing {
+				warnings = append(warnings, newFormatError(
+					pkg.Fset.Position(pos),
+					fmt.Sprintf("positional literal of target type %s has missing fields and is not filled, use field names or -convert-positional to fill it", types.TypeString(namedType, types.RelativeTo(pkg.Types))),
+				))
+			}
 			return true
 		}
 
@@ -217,38 +642,53 @@ func Format(pkg *packages.Package, file *ast.File, option *Option) (*FormatResul
 			}
 		}
 
-		// Rebuild elements in struct field order
-		type fieldInfo struct {
-			index     int
-			name      string
-			fieldType types.Type
-		}
-
-		var allFields []fieldInfo
-		for i := 0; i < structType.NumFields(); i++ {
-			field := structType.Field(i)
-			if !isExportedField(field.Name()) {
-				continue
-			}
-			allFields = append(allFields, fieldInfo{
-				index:     i,
-				name:      field.Name(),
-				fieldType: field.Type(),
-			})
+		// Explain why a target type with only unexported fields is left unchanged
+		if targeted && namedType != nil && len(allFields) == 0 && structType.NumFields() > 0 && !hasExportedField(structType) {
+			warnings = append(warnings, newFormatError(
+				pkg.Fset.Position(pos),
+				fmt.Sprintf("target type %s has no exported fields to fill", types.TypeString(namedType, types.RelativeTo(pkg.Types))),
+			))
+			trace(pos, "%s literal skipped: no exported fields", typeName)
+			return true
 		}
 
 		// Check if any fields are missing
-		hasMissing := false
+		report := &LiteralReport{
+			Type:     types.TypeString(litType, nil),
+			Position: pkg.Fset.Position(pos),
+		}
+		if namedType != nil {
+			report.Type = types.TypeString(namedType, nil)
+		}
 		for _, field := range allFields {
 			if !presentFields[field.name] {
-				hasMissing = true
-				break
+				report.Missing = append(report.Missing, field.name)
 			}
 		}
+		if !generated {
+			literals = append(literals, report)
+		}
 
-		if !hasMissing {
+		if len(report.Missing) == 0 {
+			trace(pos, "%s literal is complete", typeName)
 			return true
 		}
+		trace(pos, "%s literal filled: %s", typeName, strings.Join(report.Missing, ", "))
+
+		// Struct values added below are filled after this literal, inside it
+		state.fillPos = pos
+		state.filling = gen.enclosing
+		if generated && namedType != nil {
+			state.filling = append(slices.Clip(gen.enclosing), types.TypeString(namedType, nil))
+		}
+
+		// Elements of the input, to insert the added ones after them in the edits
+		input := make(map[dst.Expr]ast.Expr)
+		if !generated {
+			for i, elt := range lit.Elts {
+				input[elt] = astLit.Elts[i]
+			}
+		}
 
 		// Build new elements list in struct field order
 		var newElts []dst.Expr
@@ -266,204 +706,1571 @@ func Format(pkg *packages.Package, file *ast.File, option *Option) (*FormatResul
 			}
 		}
 
+		// Keep standalone comments next to the same field and at the end of the literal
+		var trailing []string
+		if len(lit.Elts) > 0 {
+			if !appendSorted {
+				moveLbraceComments(lit)
+			}
+			trailing = cutTrailingComments(lit.Elts[len(lit.Elts)-1])
+		}
+
+		if appendSorted {
+			// Keep existing elements where they are and append missing fields after them
+			newElts = append(newElts, lit.Elts...)
+		}
+
+		// Existing elements are kept for all fields, including those that are not filled
+		fillable := make(map[int]structField, len(allFields))
 		for _, field := range allFields {
-			if kv, ok := existingKVs[field.name]; ok {
+			fillable[field.index] = field
+		}
+		for i := 0; i < structType.NumFields(); i++ {
+			if kv, ok := existingKVs[structType.Field(i).Name()]; ok {
 				// Use existing KeyValueExpr
-				newElts = append(newElts, kv)
-			} else {
-				// Create new KeyValueExpr for missing field
-				zeroValue := generateZeroValue(field.fieldType, pkg, option)
-				newKV := &dst.KeyValueExpr{
-					Key:   &dst.Ident{Name: field.name},
-					Value: zeroValue,
+				if !appendSorted {
+					newElts = append(newElts, kv)
 				}
+				continue
+			}
+			field, ok := fillable[i]
+			if !ok {
+				continue
+			}
+
+			// Create new KeyValueExpr for missing field
+			zeroValue := fieldValue(field, namedType, pkg, option, state)
+			for _, message := range state.warnings {
+				warnings = append(warnings, newFormatError(pkg.Fset.Position(pos), message))
+			}
+			state.warnings = nil
+			newKV := &dst.KeyValueExpr{
+				Key:   &dst.Ident{Name: field.name},
+				Value: zeroValue,
+			}
+
+			// Copy line breaks from existing element if available, without blank lines
+			if sampleKV != nil {
+				newKV.Decs.Before = withoutEmptyLine(sampleKV.Decs.Before)
+				newKV.Decs.After = withoutEmptyLine(sampleKV.Decs.After)
+			} else {
+				newKV.Decs.Before = dst.NewLine
+				newKV.Decs.After = dst.NewLine
+			}
 
-				// Copy decorations from existing element if available
-				if sampleKV != nil {
-					newKV.Decs.Before = sampleKV.Decs.Before
-					newKV.Decs.After = sampleKV.Decs.After
-				} else {
+			if option.SourceRefComments {
+				if ref := sourceRef(pkg, field.field); ref != "" {
+					// A line comment ends the line, so the field cannot share it with others
 					newKV.Decs.Before = dst.NewLine
 					newKV.Decs.After = dst.NewLine
+					newKV.Decs.End.Append("// field defined at " + ref)
 				}
-
-				newElts = append(newElts, newKV)
 			}
+
+			newElts = append(newElts, newKV)
+		}
+
+		if len(trailing) > 0 {
+			newElts[len(newElts)-1].Decorations().End.Append(trailing...)
+		}
+		// A field moved to the top does not keep a blank line after the opening brace
+		if first := newElts[0].Decorations(); !appendSorted && first.Before == dst.EmptyLine {
+			first.Before = dst.NewLine
 		}
 
+		// Only the elements are replaced; the literal's type, or its elision, is kept as written
 		lit.Elts = newElts
+		if !generated {
+			fills = append(fills, &literalFill{lit: astLit, report: report, elts: newElts, input: input, keys: keys})
+		}
 
 		changed = true
 		return true
 	})
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	if !changed {
 		return &FormatResult{
-			Path:    path,
-			Output:  nil,
-			Errors:  errors,
-			Changed: false,
+			Path:     path,
+			Output:   nil,
+			Errors:   errors,
+			Warnings: warnings,
+			Changed:  false,
+			Literals: literals,
+
+			MatchedTargets: sortedKeys(matched),
 		}, nil
 	}
 
+	// Edits are built from the input positions, so before the file is changed further
+	tokFile := pkg.Fset.File(file.Pos())
+	if err := setLiteralEdits(tokFile, fills); err != nil {
+		return nil, err
+	}
+
+	// Add imports for packages referenced by generated values
+	added := addImports(dstFile, state.imports)
+
 	// Print dst.File with decorations preserved
 	var buf bytes.Buffer
 	if err := decorator.Fprint(&buf, dstFile); err != nil {
 		return nil, fmt.Errorf("failed to print dst file: %w", err)
 	}
 
-	// Format the output
-	formatted, err := format.Source(buf.Bytes())
-	if err != nil {
-		return nil, fmt.Errorf("failed to format source: %w", err)
+	// Format the output unless the caller wants the raw printer output
+	formatted := buf.Bytes()
+	if !option.SkipFinalFormat {
+		formatted, err = format.Source(formatted)
+		if err != nil {
+			return nil, fmt.Errorf("failed to format source: %w", err)
+		}
 	}
 
 	return &FormatResult{
-		Path:    path,
-		Output:  formatted,
-		Errors:  errors,
-		Changed: true,
+		Path:     path,
+		Output:   formatted,
+		Errors:   errors,
+		Warnings: warnings,
+		Changed:  true,
+		Literals: literals,
+
+		ImportEdits:    importEdits(tokFile, file, added, state.imports),
+		MatchedTargets: sortedKeys(matched),
 	}, nil
 }
 
-// isAllKeyed checks if all elements in the composite literal are keyed
-func isAllKeyed(elts []dst.Expr) bool {
-	if len(elts) == 0 {
-		return true
+// sortedKeys returns the keys of the set in order, or nil when it is empty
+func sortedKeys(set map[string]bool) []string {
+	var keys []string
+	for key := range set {
+		keys = append(keys, key)
 	}
+	sort.Strings(keys)
+	return keys
+}
 
-	for _, elt := range elts {
-		if _, ok := elt.(*dst.KeyValueExpr); !ok {
-			return false
+// decorateFile converts ast.File to dst.File, turning panics on malformed ASTs into errors.
+// It is a variable so that tests can simulate decoration failures.
+var decorateFile = func(dec *decorator.Decorator, file *ast.File) (dstFile *dst.File, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic while decorating: %v", r)
 		}
-	}
-	return true
+	}()
+	return dec.DecorateFile(file)
 }
 
-// isExportedField checks if a field name is exported
-func isExportedField(name string) bool {
-	if name == "" {
-		return false
-	}
-	r, _ := utf8.DecodeRuneInString(name)
-	return unicode.IsUpper(r)
+// fillRegion is a source range enclosed by //fillstruct:begin and //fillstruct:end markers
+type fillRegion struct {
+	start token.Pos
+	end   token.Pos
 }
 
-// getCustomDefault returns the custom default constant name for the given named type
-func getCustomDefault(named *types.Named, opt *Option) string {
-	if opt.CustomDefaults == nil {
-		return ""
+// findFillRegions returns the regions marked for filling in the file.
+// A begin marker without a matching end marker extends to the end of the file.
+func findFillRegions(file *ast.File) []fillRegion {
+	var regions []fillRegion
+	var start token.Pos
+	for _, group := range file.Comments {
+		for _, comment := range group.List {
+			switch strings.TrimSpace(comment.Text) {
+			case "//fillstruct:begin":
+				if !start.IsValid() {
+					start = comment.End()
+				}
+			case "//fillstruct:end":
+				if start.IsValid() {
+					regions = append(regions, fillRegion{start: start, end: comment.Pos()})
+					start = token.NoPos
+				}
+			}
+		}
+	}
+	if start.IsValid() {
+		regions = append(regions, fillRegion{start: start, end: file.End()})
 	}
+	return regions
+}
 
-	obj := named.Obj()
-	if obj == nil || obj.Pkg() == nil {
-		return ""
+// inFillRegions checks if the position is inside one of the regions
+func inFillRegions(regions []fillRegion, pos token.Pos) bool {
+	for _, r := range regions {
+		if r.start <= pos && pos < r.end {
+			return true
+		}
 	}
+	return false
+}
 
-	// Build the fully qualified type name
-	typeSpec := obj.Pkg().Path() + "." + obj.Name()
+// parseExpr parses a Go expression into a dst.Expr
+func parseExpr(s string) (dst.Expr, error) {
+	fset := token.NewFileSet()
+	expr, err := parser.ParseExprFrom(fset, "", s, 0)
+	if err != nil {
+		return nil, err
+	}
+	node, err := decorator.NewDecorator(fset).DecorateNode(expr)
+	if err != nil {
+		return nil, err
+	}
+	return node.(dst.Expr), nil
+}
 
-	if constantName, ok := opt.CustomDefaults[typeSpec]; ok {
-		return constantName
+// matchesShape checks if the anonymous struct is identical to one of the shapes
+func matchesShape(structType *types.Struct, shapes []*types.Struct) bool {
+	for _, shape := range shapes {
+		if types.Identical(structType, shape) {
+			return true
+		}
 	}
+	return false
+}
 
-	return ""
+// filePos converts a line and column in the file to a token.Pos
+func filePos(tokFile *token.File, position token.Position) (token.Pos, error) {
+	if position.Line > tokFile.LineCount() || position.Column < 1 {
+		return token.NoPos, fmt.Errorf("position %d:%d is outside of %s", position.Line, position.Column, tokFile.Name())
+	}
+	offset := tokFile.Offset(tokFile.LineStart(position.Line)) + position.Column - 1
+	if offset > tokFile.Size() {
+		return token.NoPos, fmt.Errorf("position %d:%d is outside of %s", position.Line, position.Column, tokFile.Name())
+	}
+	return tokFile.Pos(offset), nil
 }
 
-// generateZeroValue generates a zero value expression for the given type
-func generateZeroValue(t types.Type, pkg *packages.Package, opt *Option) dst.Expr {
-	// Check for custom default for Named types
-	if named, ok := t.(*types.Named); ok {
-		if customDefault := getCustomDefault(named, opt); customDefault != "" {
-			return &dst.Ident{Name: customDefault}
+// cgoFile reports whether the file is translated by cgo or imports "C". The packages
+// loader parses the compiled files, which for cgo are cgo's output in the build cache
+// rather than one of the GoFiles. Without the list of files (e.g., for FormatFile),
+// cgo's output is recognized by its header.
+func cgoFile(pkg *packages.Package, file *ast.File) bool {
+	for _, spec := range file.Imports {
+		if spec.Path.Value == `"C"` {
+			return true
 		}
 	}
-
-	// Check for custom default for Basic types
-	if basic, ok := t.(*types.Basic); ok {
-		if opt.CustomDefaults != nil {
-			if constantName, ok := opt.CustomDefaults[basic.Name()]; ok {
-				return &dst.Ident{Name: constantName}
-			}
+	if tokFile := pkg.Fset.File(file.Pos()); tokFile != nil && len(pkg.GoFiles) > 0 {
+		return !slices.Contains(pkg.GoFiles, tokFile.Name())
+	}
+	for _, group := range file.Comments {
+		if group.Pos() > file.Package {
+			break
+		}
+		if strings.HasPrefix(group.Text(), "Code generated by cmd/cgo;") {
+			return true
 		}
 	}
+	return false
+}
 
-	switch t := t.(type) {
-	case *types.Basic:
-		switch t.Kind() {
-		case types.Bool:
-			return &dst.Ident{Name: "false"}
-		case types.String:
-			return &dst.BasicLit{Kind: token.STRING, Value: `""`}
-		case types.Int, types.Int8, types.Int16, types.Int32, types.Int64,
-			types.Uint, types.Uint8, types.Uint16, types.Uint32, types.Uint64,
-			types.Uintptr, types.Float32, types.Float64, types.Complex64, types.Complex128:
-			return &dst.BasicLit{Kind: token.INT, Value: "0"}
-		default:
-			return &dst.Ident{Name: "nil"}
+// structLiteralAt returns the innermost struct literal enclosing pos, or nil if there is none
+func structLiteralAt(info *types.Info, file *ast.File, pos token.Pos) *ast.CompositeLit {
+	var found *ast.CompositeLit
+	ast.Inspect(file, func(n ast.Node) bool {
+		if n == nil || pos < n.Pos() || pos > n.End() {
+			return false
 		}
-
-	case *types.Pointer, *types.Slice, *types.Map, *types.Chan, *types.Signature, *types.Interface:
-		return &dst.Ident{Name: "nil"}
-
-	case *types.Struct:
-		return &dst.CompositeLit{}
-
-	case *types.Named:
-		underlying := t.Underlying()
-		// Check if the underlying type is an interface
-		if _, ok := underlying.(*types.Interface); ok {
-			return &dst.Ident{Name: "nil"}
+		lit, ok := n.(*ast.CompositeLit)
+		if !ok {
+			return true
 		}
-		// If underlying type is a basic type, return its zero value
-		if basic, ok := underlying.(*types.Basic); ok {
-			return generateZeroValue(basic, pkg, opt)
-		}
-		// For named types with struct underlying, get the type name and create a composite literal
-		typeName := t.Obj().Name()
-		if pkgPath := t.Obj().Pkg(); pkgPath != nil && pkgPath.Path() != pkg.Types.Path() {
-			// Need to qualify with package name
-			return &dst.CompositeLit{
-				Type: &dst.SelectorExpr{
-					X:   &dst.Ident{Name: pkgPath.Name()},
-					Sel: &dst.Ident{Name: typeName},
-				},
-			}
+		t := info.TypeOf(lit)
+		if ptr, ok := t.(*types.Pointer); ok {
+			t = ptr.Elem()
 		}
-		return &dst.CompositeLit{
-			Type: &dst.Ident{Name: typeName},
+		if t != nil {
+			if _, ok := t.Underlying().(*types.Struct); ok {
+				found = lit
+			}
 		}
+		return true
+	})
+	return found
+}
 
-	case *types.Array:
-		return &dst.CompositeLit{
-			Type: &dst.ArrayType{
-				Len: &dst.BasicLit{Kind: token.INT, Value: fmt.Sprintf("%d", t.Len())},
-				Elt: typeToExpr(t.Elem()),
-			},
-		}
+// sameNamedType reports whether the named type is the target type
+func sameNamedType(namedType, targetType *types.Named, option *Option) bool {
+	if namedType.Obj() == targetType.Obj() {
+		return true
+	}
+	// Compare by package path and type name instead of types.Identical
+	// because they may be from different package loads
+	if namedType.Obj().Pkg() == nil || targetType.Obj().Pkg() == nil ||
+		normalizePath(namedType.Obj().Pkg().Path(), option) != normalizePath(targetType.Obj().Pkg().Path(), option) ||
+		namedType.Obj().Name() != targetType.Obj().Name() {
+		return false
+	}
+	// A different package with the same path (e.g., a vendored copy) only matches
+	// when it declares the same fields
+	return namedType.Obj().Pkg() == targetType.Obj().Pkg() || sameFields(namedType, targetType, option)
+}
+
+// normalizePath returns the package path compared for Option.NormalizePaths: without the
+// prefix up to a "vendor" element and with replaced module paths mapped to the module
+// they replace. It returns the path unchanged when NormalizePaths is not set.
+func normalizePath(pkgPath string, option *Option) string {
+	if !option.NormalizePaths {
+		return pkgPath
+	}
+	if i := strings.LastIndex(pkgPath, "/vendor/"); i >= 0 {
+		pkgPath = pkgPath[i+len("/vendor/"):]
+	} else if rest, ok := strings.CutPrefix(pkgPath, "vendor/"); ok {
+		pkgPath = rest
+	}
+	// The longest module path containing the package wins, as for nested modules
+	var replaced, module string
+	for from, to := range option.ModuleReplacements {
+		if (pkgPath == from || strings.HasPrefix(pkgPath, from+"/")) && len(from) > len(replaced) {
+			replaced, module = from, to
+		}
+	}
+	if replaced != "" {
+		pkgPath = module + pkgPath[len(replaced):]
+	}
+	return pkgPath
+}
+
+// isExcludedType reports whether the named type is one of the excluded types
+func isExcludedType(namedType *types.Named, option *Option) bool {
+	for _, excluded := range option.ExcludeTypes {
+		if sameNamedType(namedType, excluded, option) {
+			return true
+		}
+	}
+	return false
+}
+
+// packageStructTypes returns the named struct types declared in the first loaded package
+// without errors, in name order. Aliases are skipped, as the types they denote are
+// declared elsewhere.
+func packageStructTypes(pkgs []*packages.Package, importPath string) ([]*types.Named, error) {
+	for _, pkg := range pkgs {
+		if len(pkg.Errors) > 0 {
+			continue
+		}
+		var structs []*types.Named
+		scope := pkg.Types.Scope()
+		for _, name := range scope.Names() {
+			obj, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || obj.IsAlias() {
+				continue
+			}
+			named, ok := obj.Type().(*types.Named)
+			if !ok {
+				continue
+			}
+			if _, ok := named.Underlying().(*types.Struct); ok {
+				structs = append(structs, named)
+			}
+		}
+		if len(structs) == 0 {
+			return nil, fmt.Errorf("no struct types found in package %q", importPath)
+		}
+		return structs, nil
+	}
+	return nil, fmt.Errorf("errors in package %q: %v", importPath, pkgs[0].Errors)
+}
+
+// hasCandidateLiteral reports whether the file contains a composite literal of a struct
+// type that Format would consider filling. It only looks at the type of each literal, so
+// it may report literals that end up unchanged, but never misses one that would change.
+func hasCandidateLiteral(file *ast.File, info *types.Info, pkg *packages.Package, option *Option) bool {
+	targeted := len(option.TargetTypes) > 0 || len(option.TargetTypeNames) > 0
+	found := false
+	ast.Inspect(file, func(n ast.Node) bool {
+		if found {
+			return false
+		}
+		lit, ok := n.(*ast.CompositeLit)
+		if !ok {
+			return true
+		}
+		tv, ok := info.Types[lit]
+		if !ok {
+			return true
+		}
+
+		typ := types.Unalias(tv.Type)
+		if ptr, ok := typ.(*types.Pointer); ok {
+			typ = types.Unalias(ptr.Elem())
+		}
+		if _, ok := typ.Underlying().(*types.Struct); !ok {
+			return true
+		}
+		namedType, ok := typ.(*types.Named)
+		switch {
+		case !ok:
+			found = option.IncludeAnonymous || !option.SkipAnonymous && !targeted
+		case isExcludedType(namedType, option):
+		case targeted:
+			found = isTargetType(namedType, pkg, option)
+		default:
+			found = true
+		}
+		return true
+	})
+	return found
+}
+
+// isTargetType checks if the named type matches one of the target types
+func isTargetType(namedType *types.Named, pkg *packages.Package, option *Option) bool {
+	_, ok := matchTargetType(namedType, pkg, option)
+	return ok
+}
+
+// matchTargetType returns the target type the named type matches, as reported in
+// FormatResult.MatchedTargets
+func matchTargetType(namedType *types.Named, pkg *packages.Package, option *Option) (string, bool) {
+	for _, targetType := range option.TargetTypes {
+		if sameNamedType(namedType, targetType, option) {
+			return types.TypeString(targetType, nil), true
+		}
+	}
+
+	// Bare type names only match types declared in the package being formatted
+	if namedType.Obj().Pkg() == nil || namedType.Obj().Pkg().Path() != pkg.Types.Path() {
+		return "", false
+	}
+	for _, name := range option.TargetTypeNames {
+		if namedType.Obj().Name() == name {
+			return name, true
+		}
+	}
+
+	return "", false
+}
+
+// sourceRef returns the file and line declaring the field, e.g., "user.go:12" for fields
+// of the package being formatted or "example.com/models/user.go:12" for other packages.
+// It returns an empty string when the position is unknown.
+func sourceRef(pkg *packages.Package, field *types.Var) string {
+	position := pkg.Fset.Position(field.Pos())
+	if !position.IsValid() || position.Filename == "" {
+		return ""
+	}
+	file := filepath.Base(position.Filename)
+	if field.Pkg() != nil && field.Pkg().Path() != pkg.Types.Path() {
+		file = field.Pkg().Path() + "/" + file
+	}
+	return fmt.Sprintf("%s:%d", file, position.Line)
+}
+
+// moveLbraceComments moves comments on their own lines after the opening brace of the
+// literal to the first element, so they stay above it when the elements are reordered
+func moveLbraceComments(lit *dst.CompositeLit) {
+	lbrace := lit.Decs.Lbrace.All()
+	if len(lbrace) == 0 || lbrace[0] != "\n" {
+		// Nothing, or a comment on the line of the brace, which stays there
+		return
+	}
+
+	first := lit.Elts[0].Decorations()
+	comments := lbrace[1:]
+	if first.Before == dst.EmptyLine {
+		// Keep the blank line between the comments and the element
+		comments = append(comments, "\n")
+		first.Before = dst.NewLine
+	}
+	first.Start.Prepend(comments...)
+	lit.Decs.Lbrace.Clear()
+}
+
+// cutTrailingComments removes and returns the comments on their own lines after the
+// element, which belong to the end of the literal rather than to the element
+func cutTrailingComments(elt dst.Expr) []string {
+	end := elt.Decorations().End.All()
+	for i, dec := range end {
+		if dec == "\n" {
+			elt.Decorations().End.Replace(end[:i]...)
+			return end[i:]
+		}
+	}
+	return nil
+}
+
+// withoutEmptyLine returns NewLine for EmptyLine so blank lines separating groups of
+// existing fields are not repeated between added fields
+func withoutEmptyLine(space dst.SpaceType) dst.SpaceType {
+	if space == dst.EmptyLine {
+		return dst.NewLine
+	}
+	return space
+}
+
+// sameFields reports whether the struct types declare the same field names, tags and
+// types, comparing types by package path, normalized as for Option.NormalizePaths, so
+// that types from different loads match
+func sameFields(a, b *types.Named, option *Option) bool {
+	sa, ok := a.Underlying().(*types.Struct)
+	if !ok {
+		return false
+	}
+	sb, ok := b.Underlying().(*types.Struct)
+	if !ok || sa.NumFields() != sb.NumFields() {
+		return false
+	}
+	qualifier := func(p *types.Package) string { return normalizePath(p.Path(), option) }
+	for i := 0; i < sa.NumFields(); i++ {
+		fa, fb := sa.Field(i), sb.Field(i)
+		if fa.Name() != fb.Name() || fa.Embedded() != fb.Embedded() || sa.Tag(i) != sb.Tag(i) ||
+			types.TypeString(fa.Type(), qualifier) != types.TypeString(fb.Type(), qualifier) {
+			return false
+		}
+	}
+	return true
+}
+
+// isAllKeyed checks if all elements in the composite literal are keyed
+func isAllKeyed(elts []dst.Expr) bool {
+	if len(elts) == 0 {
+		return true
+	}
+
+	for _, elt := range elts {
+		if _, ok := elt.(*dst.KeyValueExpr); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// isAllPositional checks if no element in the composite literal is keyed
+func isAllPositional(elts []dst.Expr) bool {
+	for _, elt := range elts {
+		if _, ok := elt.(*dst.KeyValueExpr); ok {
+			return false
+		}
+	}
+	return true
+}
+
+// keyPositional converts positional elements to key-value pairs of the fields in
+// declaration order, moving the decorations of each element to its pair
+func keyPositional(elts []dst.Expr, structType *types.Struct) {
+	for i, elt := range elts {
+		kv := &dst.KeyValueExpr{
+			Key:   &dst.Ident{Name: structType.Field(i).Name()},
+			Value: elt,
+		}
+		kv.Decs.NodeDecs = *elt.Decorations()
+		*elt.Decorations() = dst.NodeDecs{}
+		elts[i] = kv
+	}
+}
+
+// fieldNameSelected reports whether the field name passes Option.FieldInclude and
+// Option.FieldExclude. Malformed patterns match nothing.
+func fieldNameSelected(name string, opt *Option) bool {
+	matchAny := func(patterns []string) bool {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, name); ok {
+				return true
+			}
+		}
+		return false
+	}
+	if len(opt.FieldInclude) > 0 && !matchAny(opt.FieldInclude) {
+		return false
+	}
+	return !matchAny(opt.FieldExclude)
+}
+
+// isFillableField reports whether the field may be set in a literal of the package:
+// exported fields, and unexported fields declared in the package with Option.IncludeUnexported
+func isFillableField(field *types.Var, pkg *packages.Package, opt *Option) bool {
+	if isExportedField(field.Name()) {
+		return true
+	}
+	return opt.IncludeUnexported && field.Pkg() == pkg.Types
+}
+
+// hasExportedField checks if the struct has at least one exported field
+func hasExportedField(s *types.Struct) bool {
+	for i := 0; i < s.NumFields(); i++ {
+		if isExportedField(s.Field(i).Name()) {
+			return true
+		}
+	}
+	return false
+}
+
+// isExportedField checks if a field name is exported
+func isExportedField(name string) bool {
+	if name == "" {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(name)
+	return unicode.IsUpper(r)
+}
+
+// getCustomDefault returns the custom default constant name for the given named type
+func getCustomDefault(named *types.Named, opt *Option) string {
+	if opt.CustomDefaults == nil {
+		return ""
+	}
+
+	obj := named.Obj()
+	if obj == nil || obj.Pkg() == nil {
+		return ""
+	}
+
+	// Build the fully qualified type name
+	typeSpec := obj.Pkg().Path() + "." + obj.Name()
+
+	if constantName, ok := opt.CustomDefaults[typeSpec]; ok {
+		return constantName
+	}
+
+	return ""
+}
+
+// customNamedDefaultExpr returns the expression for a custom default of a named type.
+// Constants of types declared in another package are qualified with that package, whether
+// the name is given bare ("StatusUnknown") or qualified ("otherpkg.StatusUnknown"),
+// and the package is imported if needed. Other values are used verbatim.
+func customNamedDefaultExpr(value string, named *types.Named, pkg *packages.Package, state *fileState) dst.Expr {
+	typePkg := named.Obj().Pkg()
+	if typePkg == pkg.Types {
+		return &dst.Ident{Name: value}
+	}
+	name := value
+	if qualifier, rest, ok := strings.Cut(value, "."); ok {
+		if qualifier != typePkg.Name() {
+			return &dst.Ident{Name: value}
+		}
+		name = rest
+	}
+	if !token.IsIdentifier(name) {
+		return &dst.Ident{Name: value}
+	}
+	return &dst.SelectorExpr{
+		X:   &dst.Ident{Name: state.qualifier(typePkg)},
+		Sel: &dst.Ident{Name: name},
+	}
+}
+
+// interfaceDefaultExpr returns the expression configured in Option.InterfaceDefaults for
+// the named interface, or nil if there is none. Qualifiers of package-level names in the
+// expression are resolved among the dependencies of pkg and the packages are imported.
+func interfaceDefaultExpr(named *types.Named, pkg *packages.Package, opt *Option, state *fileState) dst.Expr {
+	obj := named.Obj()
+	if obj.Pkg() == nil {
+		return nil
+	}
+	value, ok := opt.InterfaceDefaults[obj.Pkg().Path()+"."+obj.Name()]
+	if !ok {
+		return nil
+	}
+	expr, err := parseExpr(value)
+	if err != nil {
+		return nil
+	}
+
+	dst.Inspect(expr, func(n dst.Node) bool {
+		sel, ok := n.(*dst.SelectorExpr)
+		if !ok {
+			return true
+		}
+		ident, ok := sel.X.(*dst.Ident)
+		if !ok {
+			return true
+		}
+		dep := obj.Pkg()
+		if ident.Name != dep.Name() {
+			dep = deps.Find(pkg.Types, func(p *types.Package) bool {
+				return p.Name() == ident.Name
+			})
+		}
+		if dep != nil && dep != pkg.Types {
+			ident.Name = state.qualifier(dep)
+		}
+		return false
+	})
+	return expr
+}
+
+// renamePackages renames the package identifiers qualifying names in the expression,
+// e.g., money.Zero() to money2.Zero() for the rename "money" -> "money2"
+func renamePackages(expr dst.Expr, renames map[string]string) {
+	dst.Inspect(expr, func(n dst.Node) bool {
+		sel, ok := n.(*dst.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if ident, ok := sel.X.(*dst.Ident); ok {
+			if name, ok := renames[ident.Name]; ok {
+				ident.Name = name
+			}
+			return false
+		}
+		return true
+	})
+}
+
+// fileState holds state scoped to a single Format call.
+// Format runs concurrently for different files, so it must not be shared between calls;
+// in particular the imports collected for generated values belong to a single file.
+type fileState struct {
+	zeroConsts  map[*types.TypeName]*types.Const
+	docDefaults map[*types.TypeName]map[string]string // field name -> value from the doc comment
+	imports     map[string]string                     // import path -> package name referenced by generated values
+	imported    map[string]string                     // import path -> name the file imports the package under
+
+	expanding map[string]bool // struct types being filled by Option.Recursive, by type string
+
+	// generated holds the struct literals added for missing fields, which are filled in
+	// the same pass; filling lists the generated literals enclosing the values being added,
+	// by type string, and fillPos is the input literal they belong to
+	generated map[*dst.CompositeLit]generatedLit
+	filling   []string
+	fillPos   token.Pos
+
+	placeholder     dst.Expr // parsed Option.UnknownPlaceholder
+	placeholderUses int
+
+	warnings []string // problems with values added for missing fields, see fieldValue
+}
+
+// generatedLit is a struct literal added for a missing field
+type generatedLit struct {
+	typ       types.Type // a named or anonymous struct type
+	pos       token.Pos  // the input literal it was added to
+	enclosing []string   // types of the generated literals it is nested in
+}
+
+// newFileState returns the state for formatting the file. info resolves the names of
+// packages imported without an alias; the last element of the path is assumed without it.
+func newFileState(file *ast.File, info *types.Info) *fileState {
+	s := &fileState{
+		zeroConsts:  make(map[*types.TypeName]*types.Const),
+		docDefaults: make(map[*types.TypeName]map[string]string),
+		imports:     make(map[string]string),
+		imported:    make(map[string]string),
+		expanding:   make(map[string]bool),
+		generated:   make(map[*dst.CompositeLit]generatedLit),
+	}
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		var name string
+		switch {
+		case spec.Name != nil:
+			name = spec.Name.Name
+		case info != nil && info.PkgNameOf(spec) != nil:
+			name = info.PkgNameOf(spec).Imported().Name()
+		default:
+			name = path.Base(importPath)
+		}
+		// Blank and dot imports cannot qualify names
+		if name == "_" || name == "." {
+			continue
+		}
+		s.imported[importPath] = name
+	}
+	return s
+}
+
+// docDefault returns the value for the field listed in the "Default:" line of the doc
+// comment of the named type, or nil if there is none or it does not parse
+func (s *fileState) docDefault(pkg *packages.Package, named *types.Named, field string) dst.Expr {
+	obj := named.Obj()
+	defaults, ok := s.docDefaults[obj]
+	if !ok {
+		defaults = parseDocDefaults(typeDoc(pkg, obj))
+		s.docDefaults[obj] = defaults
+	}
+	value, ok := defaults[field]
+	if !ok {
+		return nil
+	}
+	expr, err := parseExpr(value)
+	if err != nil {
+		return nil
+	}
+	return expr
+}
+
+// typeDoc returns the doc comment of the type declaration in the syntax of the package
+// or its imports, or nil if the declaration is not loaded
+func typeDoc(pkg *packages.Package, obj *types.TypeName) *ast.CommentGroup {
+	var doc *ast.CommentGroup
+	seen := make(map[*packages.Package]bool)
+	var visit func(p *packages.Package) bool
+	visit = func(p *packages.Package) bool {
+		if seen[p] {
+			return false
+		}
+		seen[p] = true
+		if p.Types != nil && p.Types == obj.Pkg() {
+			for _, file := range p.Syntax {
+				if obj.Pos() < file.Pos() || obj.Pos() >= file.End() {
+					continue
+				}
+				for _, decl := range file.Decls {
+					gen, ok := decl.(*ast.GenDecl)
+					if !ok || gen.Tok != token.TYPE {
+						continue
+					}
+					for _, spec := range gen.Specs {
+						ts := spec.(*ast.TypeSpec)
+						if ts.Name.Pos() != obj.Pos() {
+							continue
+						}
+						doc = ts.Doc
+						if doc == nil && len(gen.Specs) == 1 {
+							doc = gen.Doc
+						}
+						return true
+					}
+				}
+			}
+			return true
+		}
+		for _, imp := range p.Imports {
+			if visit(imp) {
+				return true
+			}
+		}
+		return false
+	}
+	visit(pkg)
+	return doc
+}
+
+// parseDocDefaults parses lines like "Default: Timeout=30, Retries=3" in the doc comment.
+// Malformed entries are skipped.
+func parseDocDefaults(doc *ast.CommentGroup) map[string]string {
+	defaults := make(map[string]string)
+	if doc == nil {
+		return defaults
+	}
+	for _, line := range strings.Split(doc.Text(), "\n") {
+		entries, ok := strings.CutPrefix(strings.TrimSpace(line), "Default:")
+		if !ok {
+			continue
+		}
+		for _, entry := range strings.Split(entries, ",") {
+			name, value, ok := strings.Cut(entry, "=")
+			name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+			if !ok || !token.IsIdentifier(name) || value == "" {
+				continue
+			}
+			defaults[name] = value
+		}
+	}
+	return defaults
+}
+
+// qualifier returns the identifier used to qualify names from the package,
+// recording the package so that its import is added to the file. Packages the
+// file imports are qualified with the name in effect, including aliases. A package
+// whose name is taken by another import is imported under a numbered alias (e.g., time2).
+func (s *fileState) qualifier(p *types.Package) string {
+	return s.qualifierPath(p.Path(), p.Name())
+}
+
+// qualifierPath is qualifier for the package with the import path and package name.
+// Packages are told apart by path, so two packages with the same name (e.g., two
+// "config" packages) never share an identifier.
+func (s *fileState) qualifierPath(importPath, pkgName string) string {
+	if name, ok := s.imported[importPath]; ok {
+		return name
+	}
+	if name, ok := s.imports[importPath]; ok {
+		return name
+	}
+	name := pkgName
+	for i := 2; s.nameTaken(name); i++ {
+		name = pkgName + strconv.Itoa(i)
+	}
+	s.imports[importPath] = name
+	return name
+}
+
+// nameTaken reports whether an import of the file or an added import uses the name
+func (s *fileState) nameTaken(name string) bool {
+	for _, imports := range []map[string]string{s.imported, s.imports} {
+		for _, used := range imports {
+			if used == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// zeroConstant returns the constant holding the zero value of the named type, caching
+// the result so the package scope is scanned only once per type
+func (s *fileState) zeroConstant(named *types.Named) *types.Const {
+	obj := named.Obj()
+	if c, ok := s.zeroConsts[obj]; ok {
+		return c
+	}
+	c := findZeroConstant(named)
+	s.zeroConsts[obj] = c
+	return c
+}
+
+// findZeroConstant scans the package scope of the named type for a constant of that type
+// whose value is the zero value (e.g., StatusUnknown Status = iota).
+// It returns nil when there is no such constant or when more than one exists.
+func findZeroConstant(named *types.Named) *types.Const {
+	obj := named.Obj()
+	if obj == nil || obj.Pkg() == nil {
+		return nil
+	}
+
+	var found *types.Const
+	scope := obj.Pkg().Scope()
+	for _, name := range scope.Names() {
+		c, ok := scope.Lookup(name).(*types.Const)
+		if !ok || !types.Identical(c.Type(), named) || !isZeroConstantValue(c.Val()) {
+			continue
+		}
+		if found != nil {
+			// Ambiguous, fall back to the literal zero value
+			return nil
+		}
+		found = c
+	}
+	return found
+}
+
+// isZeroConstantValue checks if the constant value is the zero value of its kind
+func isZeroConstantValue(v constant.Value) bool {
+	switch v.Kind() {
+	case constant.Bool:
+		return !constant.BoolVal(v)
+	case constant.String:
+		return constant.StringVal(v) == ""
+	case constant.Int, constant.Float, constant.Complex:
+		return constant.Sign(v) == 0
+	default:
+		return false
+	}
+}
+
+// constantExpr returns an expression referring to the constant from the given package,
+// or nil if the constant is not accessible from it
+func constantExpr(c *types.Const, pkg *packages.Package, state *fileState) dst.Expr {
+	if c.Pkg().Path() == pkg.Types.Path() {
+		return &dst.Ident{Name: c.Name()}
+	}
+	if !c.Exported() {
+		return nil
+	}
+	return &dst.SelectorExpr{
+		X:   &dst.Ident{Name: state.qualifier(c.Pkg())},
+		Sel: &dst.Ident{Name: c.Name()},
+	}
+}
+
+// tagKey returns the struct tag key read for field directives
+func tagKey(opt *Option) string {
+	if opt.TagKey != "" {
+		return opt.TagKey
+	}
+	return "fillstruct"
+}
+
+// tagSkip reports whether the struct tag excludes the field from filling
+// (e.g., `fillstruct:"-"`)
+func tagSkip(tag, key string) bool {
+	value, ok := reflect.StructTag(tag).Lookup(key)
+	return ok && strings.TrimSpace(value) == "-"
+}
+
+// tagDefault returns the value of the default key in the struct tag
+// (e.g., `fillstruct:"default=DefaultTimeout"`)
+func tagDefault(tag, key string) (string, bool) {
+	value, ok := reflect.StructTag(tag).Lookup(key)
+	if !ok {
+		return "", false
+	}
+	for _, part := range strings.Split(value, ",") {
+		if name, ok := strings.CutPrefix(strings.TrimSpace(part), "default="); ok && name != "" {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// tagDefaultExpr returns the expression for a tag default of the given field.
+// Numbers are used as literals; otherwise the value names a package-level var or const
+// in the package declaring the struct, which is qualified and imported when needed.
+func tagDefaultExpr(name string, field *types.Var, pkg *packages.Package, state *fileState) (dst.Expr, error) {
+	if _, err := strconv.ParseFloat(name, 64); err == nil {
+		return customDefaultExpr(name), nil
+	}
+	if field.Pkg() == nil {
+		return nil, fmt.Errorf("default %s cannot be resolved", name)
+	}
+
+	obj := field.Pkg().Scope().Lookup(name)
+	switch obj.(type) {
+	case *types.Var, *types.Const:
+	default:
+		return nil, fmt.Errorf("default %s is not a package-level var or const in package %s", name, field.Pkg().Name())
+	}
+	if !types.AssignableTo(obj.Type(), field.Type()) {
+		return nil, fmt.Errorf("default %s of type %s is not assignable to %s", name, obj.Type(), field.Type())
+	}
+
+	if obj.Pkg().Path() == pkg.Types.Path() {
+		return &dst.Ident{Name: obj.Name()}, nil
+	}
+	if !obj.Exported() {
+		return nil, fmt.Errorf("default %s is not exported from package %s", name, obj.Pkg().Name())
+	}
+	return &dst.SelectorExpr{
+		X:   &dst.Ident{Name: state.qualifier(obj.Pkg())},
+		Sel: &dst.Ident{Name: obj.Name()},
+	}, nil
+}
+
+// customDefaultExpr returns the expression for a custom default of a basic type,
+// using a literal of the matching kind for numbers (e.g., "8080" or "1.5")
+func customDefaultExpr(value string) dst.Expr {
+	if _, err := strconv.ParseInt(value, 0, 64); err == nil {
+		return &dst.BasicLit{Kind: token.INT, Value: value}
+	}
+	if _, err := strconv.ParseUint(value, 0, 64); err == nil {
+		return &dst.BasicLit{Kind: token.INT, Value: value}
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return &dst.BasicLit{Kind: token.FLOAT, Value: value}
+	}
+	return &dst.Ident{Name: value}
+}
+
+// generateZeroValue generates a zero value expression for the given type
+func generateZeroValue(t types.Type, pkg *packages.Package, opt *Option, state *fileState) dst.Expr {
+	// Custom generators take precedence over everything else
+	if generator, ok := opt.TypeGenerators[types.TypeString(t, nil)]; ok {
+		if expr, imports := generator(t, pkg); expr != nil {
+			// The expression refers to the packages by name, which the file may import
+			// under an alias or which may be taken by another package of the file
+			renames := make(map[string]string)
+			for _, importPath := range imports {
+				name := packageName(pkg, importPath)
+				if _, ok := renames[name]; !ok {
+					renames[name] = state.qualifierPath(importPath, name)
+				}
+			}
+			renamePackages(expr, renames)
+			return expr
+		}
+	}
+
+	// Aliases are spelled as written in literals and conversions (e.g., json.RawMessage
+	// rather than the type it denotes, which may not be accessible); t is what they denote
+	spelled := t
+	t = types.Unalias(t)
+
+	// Check for custom default for Named types
+	if named, ok := t.(*types.Named); ok {
+		if customDefault := getCustomDefault(named, opt); customDefault != "" {
+			return customNamedDefaultExpr(customDefault, named, pkg, state)
+		}
+	}
+
+	// Check for custom default for Basic types
+	if basic, ok := t.(*types.Basic); ok {
+		if opt.CustomDefaults != nil {
+			if constantName, ok := opt.CustomDefaults[basic.Name()]; ok {
+				return customDefaultExpr(constantName)
+			}
+		}
+	}
+
+	switch t := t.(type) {
+	case *types.Basic:
+		switch t.Kind() {
+		case types.Bool:
+			return &dst.Ident{Name: "false"}
+		case types.String:
+			return &dst.BasicLit{Kind: token.STRING, Value: `""`}
+		case types.Int, types.Int8, types.Int16, types.Int32, types.Int64,
+			types.Uint, types.Uint8, types.Uint16, types.Uint32, types.Uint64,
+			types.Uintptr:
+			return &dst.BasicLit{Kind: token.INT, Value: "0"}
+		case types.Float32, types.Float64, types.Complex64, types.Complex128:
+			return &dst.BasicLit{Kind: token.FLOAT, Value: "0"}
+		default:
+			return &dst.Ident{Name: "nil"}
+		}
+
+	case *types.Signature:
+		if opt.StubFuncs && typeSpellable(t, pkg.Types) {
+			return stubFuncLit(t, pkg, state)
+		}
+		return nilExpr(spelled, pkg, opt, state)
+
+	case *types.Pointer:
+		// Pointers to named structs point to an empty value with NonNilPointers, except
+		// inside a value of the same type added for a missing field, where it would recur
+		if named, ok := types.Unalias(t.Elem()).(*types.Named); ok && opt.NonNilPointers && typeSpellable(t.Elem(), pkg.Types) {
+			if st, ok := named.Underlying().(*types.Struct); ok && !slices.Contains(state.filling, types.TypeString(named, nil)) {
+				return &dst.UnaryExpr{Op: token.AND, X: namedStructLit(named, t.Elem(), st, pkg, opt, state)}
+			}
+		}
+		return nilExpr(spelled, pkg, opt, state)
+
+	case *types.Slice, *types.Map, *types.Chan, *types.Interface:
+		return nilExpr(spelled, pkg, opt, state)
+
+	case *types.Struct:
+		if !typeSpellable(spelled, pkg.Types) {
+			return unsupportedValue(state)
+		}
+		lit := &dst.CompositeLit{Type: typeToExpr(spelled, pkg, state)}
+		state.generated[lit] = generatedLit{typ: t, pos: state.fillPos, enclosing: state.filling}
+		return lit
+
+	case *types.Named:
+		underlying := t.Underlying()
+		// Check if the underlying type is an interface
+		if _, ok := underlying.(*types.Interface); ok {
+			if expr := interfaceDefaultExpr(t, pkg, opt, state); expr != nil {
+				return expr
+			}
+			return nilExpr(spelled, pkg, opt, state)
+		}
+		// If underlying type is a basic type, prefer a constant holding its zero value
+		if basic, ok := underlying.(*types.Basic); ok {
+			if c := state.zeroConstant(t); c != nil && !opt.FillZeroForNamedBasic {
+				if expr := constantExpr(c, pkg, state); expr != nil {
+					return expr
+				}
+			}
+			return generateZeroValue(basic, pkg, opt, state)
+		}
+		switch underlying.(type) {
+		case *types.Slice, *types.Map:
+			if !opt.EmptyNamedCollections {
+				return nilExpr(spelled, pkg, opt, state)
+			}
+		case *types.Signature:
+			if opt.StubFuncs && typeSpellable(underlying, pkg.Types) {
+				return stubFuncLit(underlying.(*types.Signature), pkg, state)
+			}
+			return nilExpr(spelled, pkg, opt, state)
+		case *types.Chan, *types.Pointer:
+			// Composite literals of these types are invalid
+			return nilExpr(spelled, pkg, opt, state)
+		}
+		if !typeSpellable(spelled, pkg.Types) {
+			return unsupportedValue(state)
+		}
+		// For named types with struct, array, slice or map underlying, create a composite literal
+		if st, ok := underlying.(*types.Struct); ok {
+			return namedStructLit(t, spelled, st, pkg, opt, state)
+		}
+		return &dst.CompositeLit{
+			Type: typeToExpr(spelled, pkg, state),
+		}
+
+	case *types.Array:
+		if !typeSpellable(t, pkg.Types) {
+			return unsupportedValue(state)
+		}
+		return &dst.CompositeLit{
+			Type: &dst.ArrayType{
+				Len: &dst.BasicLit{Kind: token.INT, Value: fmt.Sprintf("%d", t.Len())},
+				Elt: typeToExpr(t.Elem(), pkg, state),
+			},
+		}
 
 	default:
+		return unsupportedValue(state)
+	}
+}
+
+// unsupportedValue returns the value of a type that is not supported, or cannot be written in
+// the package of the file: the placeholder if one is configured
+func unsupportedValue(state *fileState) dst.Expr {
+	if state.placeholder != nil {
+		state.placeholderUses++
+		return dst.Clone(state.placeholder).(dst.Expr)
+	}
+	return &dst.Ident{Name: "nil"}
+}
+
+// namedStructLit returns the literal of the named struct type, spelled as typ (the named type
+// or an alias of it), with its fields filled when Option.Recursive is set
+func namedStructLit(named *types.Named, typ types.Type, st *types.Struct, pkg *packages.Package, opt *Option, state *fileState) *dst.CompositeLit {
+	lit := &dst.CompositeLit{
+		Type: typeToExpr(typ, pkg, state),
+	}
+	if opt.Recursive {
+		lit.Elts = expandStruct(named, st, pkg, opt, state)
+	}
+	state.generated[lit] = generatedLit{typ: named, pos: state.fillPos, enclosing: state.filling}
+	return lit
+}
+
+// expandStruct returns the elements filling the fields of the struct as missing fields
+// are filled, one per line, for Option.Recursive. It returns nil when the type is
+// already being filled (a cycle) or the depth limit is reached.
+func expandStruct(named *types.Named, st *types.Struct, pkg *packages.Package, opt *Option, state *fileState) []dst.Expr {
+	// Instantiations of a generic type are distinct types
+	key := types.TypeString(named, nil)
+	if state.expanding[key] || (opt.RecursiveDepth > 0 && len(state.expanding) >= opt.RecursiveDepth) {
+		return nil
+	}
+	state.expanding[key] = true
+	defer delete(state.expanding, key)
+
+	var elts []dst.Expr
+	for _, field := range fillableFields(st, named, pkg, opt) {
+		kv := &dst.KeyValueExpr{
+			Key:   &dst.Ident{Name: field.name},
+			Value: fieldValue(field, named, pkg, opt, state),
+		}
+		kv.Decs.Before = dst.NewLine
+		kv.Decs.After = dst.NewLine
+		elts = append(elts, kv)
+	}
+	return elts
+}
+
+// structField is a field of a struct type that may be added to its literals
+type structField struct {
+	index     int
+	name      string
+	fieldType types.Type
+	field     *types.Var
+	tag       string
+}
+
+// fillableFields returns the fields of the struct type of named (nil for anonymous
+// structs) that may be added to its literals, in struct field order. Only direct fields
+// are listed. Embedded fields are keyed by their type name, so promoted fields, which may
+// be ambiguous, are never added.
+func fillableFields(st *types.Struct, named *types.Named, pkg *packages.Package, opt *Option) []structField {
+	var fields []structField
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		if !isFillableField(field, pkg, opt) {
+			continue
+		}
+		if opt.ExcludeFieldRegexp != nil && opt.ExcludeFieldRegexp.MatchString(field.Name()) {
+			continue
+		}
+		if !fieldNameSelected(field.Name(), opt) {
+			continue
+		}
+		if opt.FieldFilter != nil && !opt.FieldFilter(field, named) {
+			continue
+		}
+		if tagSkip(st.Tag(i), tagKey(opt)) {
+			continue
+		}
+		fields = append(fields, structField{
+			index:     i,
+			name:      field.Name(),
+			fieldType: field.Type(),
+			field:     field,
+			tag:       st.Tag(i),
+		})
+	}
+	return fields
+}
+
+// fieldValue returns the value added for a missing field of a literal of named (nil for
+// anonymous structs): the default from its tag or from the doc comment of the type, a
+// sample value, or the zero value of its type. Problems are added to state.warnings.
+func fieldValue(field structField, named *types.Named, pkg *packages.Package, opt *Option, state *fileState) dst.Expr {
+	if name, ok := tagDefault(field.tag, tagKey(opt)); ok {
+		expr, err := tagDefaultExpr(name, field.field, pkg, state)
+		if err == nil {
+			return expr
+		}
+		state.warnings = append(state.warnings, fmt.Sprintf("field %s: %v, filled with zero value", field.name, err))
+	}
+	if opt.DocCommentDefaults && named != nil {
+		if expr := state.docDefault(pkg, named, field.name); expr != nil {
+			return expr
+		}
+	}
+	if opt.SampleValues {
+		if expr := sampleValue(field.field, opt, state); expr != nil {
+			return expr
+		}
+	}
+
+	// Fields of values added for this one report their own placeholders
+	placeholderUses := state.placeholderUses
+	expr := generateZeroValue(field.fieldType, pkg, opt, state)
+	if state.placeholderUses != placeholderUses {
+		state.warnings = append(state.warnings, fmt.Sprintf("field %s has unsupported type %s, filled with placeholder %s", field.name, field.fieldType, opt.UnknownPlaceholder))
+		state.placeholderUses = placeholderUses
+	}
+	return expr
+}
+
+// packageName returns the name of the package with the import path among the
+// dependencies of pkg, or the last element of the path if it is not a dependency
+func packageName(pkg *packages.Package, importPath string) string {
+	dep := deps.Find(pkg.Types, func(p *types.Package) bool {
+		return p.Path() == importPath
+	})
+	if dep != nil {
+		return dep.Name()
+	}
+	return path.Base(importPath)
+}
+
+// nilExpr returns nil for the type, converted to the type when TypedNil is set
+// (e.g., (*Foo)(nil) or []string(nil)). Anonymous interfaces with methods stay untyped.
+func nilExpr(t types.Type, pkg *packages.Package, opt *Option, state *fileState) dst.Expr {
+	if !opt.TypedNil {
+		return &dst.Ident{Name: "nil"}
+	}
+	if iface, ok := types.Unalias(t).(*types.Interface); ok && !iface.Empty() {
 		return &dst.Ident{Name: "nil"}
 	}
+	if !typeSpellable(t, pkg.Types) {
+		return &dst.Ident{Name: "nil"}
+	}
+
+	typ := typeToExpr(t, pkg, state)
+	switch types.Unalias(t).(type) {
+	case *types.Pointer, *types.Chan, *types.Signature:
+		// Parenthesize types that would otherwise not parse as a conversion
+		typ = &dst.ParenExpr{X: typ}
+	}
+	return &dst.CallExpr{
+		Fun:  typ,
+		Args: []dst.Expr{&dst.Ident{Name: "nil"}},
+	}
+}
+
+// namedTypeExpr returns the type expression for the named type, qualified with its package name
+// when it is declared in another package and instantiated with its type arguments if it is generic
+func namedTypeExpr(t *types.Named, pkg *packages.Package, state *fileState) dst.Expr {
+	return instantiatedTypeExpr(t.Obj(), t.TypeArgs(), pkg, state)
 }
 
-// typeToExpr converts a types.Type to a dst.Expr for use in array type expressions
-func typeToExpr(t types.Type) dst.Expr {
+// instantiatedTypeExpr returns the type expression for the type name, qualified with its
+// package name when it is declared in another package and instantiated with the type arguments
+func instantiatedTypeExpr(obj *types.TypeName, typeArgs *types.TypeList, pkg *packages.Package, state *fileState) dst.Expr {
+	typeName := obj.Name()
+	var expr dst.Expr = &dst.Ident{Name: typeName}
+	if pkgPath := obj.Pkg(); pkgPath != nil && pkgPath.Path() != pkg.Types.Path() {
+		// Need to qualify with package name
+		expr = &dst.SelectorExpr{
+			X:   &dst.Ident{Name: state.qualifier(pkgPath)},
+			Sel: &dst.Ident{Name: typeName},
+		}
+	}
+
+	switch typeArgs.Len() {
+	case 0:
+		return expr
+	case 1:
+		return &dst.IndexExpr{X: expr, Index: typeToExpr(typeArgs.At(0), pkg, state)}
+	default:
+		indices := make([]dst.Expr, typeArgs.Len())
+		for i := 0; i < typeArgs.Len(); i++ {
+			indices[i] = typeToExpr(typeArgs.At(i), pkg, state)
+		}
+		return &dst.IndexListExpr{X: expr, Indices: indices}
+	}
+}
+
+// typeToExpr converts a types.Type to a dst.Expr for use in type expressions such as
+// array element types and type arguments
+func typeToExpr(t types.Type, pkg *packages.Package, state *fileState) dst.Expr {
 	switch t := t.(type) {
 	case *types.Basic:
 		return &dst.Ident{Name: t.Name()}
 	case *types.Named:
+		return namedTypeExpr(t, pkg, state)
+	case *types.Alias:
+		return instantiatedTypeExpr(t.Obj(), t.TypeArgs(), pkg, state)
+	case *types.TypeParam:
 		return &dst.Ident{Name: t.Obj().Name()}
 	case *types.Pointer:
-		return &dst.StarExpr{X: typeToExpr(t.Elem())}
+		return &dst.StarExpr{X: typeToExpr(t.Elem(), pkg, state)}
 	case *types.Slice:
-		return &dst.ArrayType{Elt: typeToExpr(t.Elem())}
+		return &dst.ArrayType{Elt: typeToExpr(t.Elem(), pkg, state)}
 	case *types.Array:
 		return &dst.ArrayType{
 			Len: &dst.BasicLit{Kind: token.INT, Value: fmt.Sprintf("%d", t.Len())},
-			Elt: typeToExpr(t.Elem()),
+			Elt: typeToExpr(t.Elem(), pkg, state),
+		}
+	case *types.Map:
+		return &dst.MapType{
+			Key:   typeToExpr(t.Key(), pkg, state),
+			Value: typeToExpr(t.Elem(), pkg, state),
+		}
+	case *types.Chan:
+		dir := dst.SEND | dst.RECV
+		switch t.Dir() {
+		case types.SendOnly:
+			dir = dst.SEND
+		case types.RecvOnly:
+			dir = dst.RECV
+		}
+		return &dst.ChanType{Dir: dir, Value: typeToExpr(t.Elem(), pkg, state)}
+	case *types.Signature:
+		return &dst.FuncType{
+			Func:    true,
+			Params:  tupleToFieldList(t.Params(), t.Variadic(), pkg, state),
+			Results: tupleToFieldList(t.Results(), false, pkg, state),
+		}
+	case *types.Struct:
+		fields := &dst.FieldList{Opening: true, Closing: true}
+		for i := 0; i < t.NumFields(); i++ {
+			v := t.Field(i)
+			field := &dst.Field{Type: typeToExpr(v.Type(), pkg, state)}
+			if !v.Embedded() {
+				field.Names = []*dst.Ident{{Name: v.Name()}}
+			}
+			// Tags are part of the type
+			if tag := t.Tag(i); tag != "" {
+				value := strconv.Quote(tag)
+				if strconv.CanBackquote(tag) {
+					value = "`" + tag + "`"
+				}
+				field.Tag = &dst.BasicLit{Kind: token.STRING, Value: value}
+			}
+			fields.List = append(fields.List, field)
+		}
+		return &dst.StructType{Fields: fields}
+	case *types.Interface:
+		if t.Empty() {
+			return &dst.Ident{Name: "interface{}"}
+		}
+		methods := &dst.FieldList{Opening: true, Closing: true}
+		for i := 0; i < t.NumEmbeddeds(); i++ {
+			methods.List = append(methods.List, &dst.Field{Type: typeToExpr(t.EmbeddedType(i), pkg, state)})
+		}
+		for i := 0; i < t.NumExplicitMethods(); i++ {
+			method := t.ExplicitMethod(i)
+			sig := method.Type().(*types.Signature)
+			methods.List = append(methods.List, &dst.Field{
+				Names: []*dst.Ident{{Name: method.Name()}},
+				Type: &dst.FuncType{
+					Params:  tupleToFieldList(sig.Params(), sig.Variadic(), pkg, state),
+					Results: tupleToFieldList(sig.Results(), false, pkg, state),
+				},
+			})
 		}
+		return &dst.InterfaceType{Methods: methods}
 	default:
 		return &dst.Ident{Name: "interface{}"}
 	}
 }
+
+// typeSpellable reports whether typeToExpr can write the type in pkg. Unexported types,
+// fields and methods of other packages cannot be named there, so anonymous structs and
+// interfaces holding them, and types built from those, cannot be written.
+func typeSpellable(t types.Type, pkg *types.Package) bool {
+	accessible := func(exported bool, from *types.Package) bool {
+		return exported || from == nil || from.Path() == pkg.Path()
+	}
+	typeArgsSpellable := func(typeArgs *types.TypeList) bool {
+		for i := 0; i < typeArgs.Len(); i++ {
+			if !typeSpellable(typeArgs.At(i), pkg) {
+				return false
+			}
+		}
+		return true
+	}
+	tupleSpellable := func(tuple *types.Tuple) bool {
+		for i := 0; i < tuple.Len(); i++ {
+			if !typeSpellable(tuple.At(i).Type(), pkg) {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch t := t.(type) {
+	case *types.Named:
+		return accessible(t.Obj().Exported(), t.Obj().Pkg()) && typeArgsSpellable(t.TypeArgs())
+	case *types.Alias:
+		return accessible(t.Obj().Exported(), t.Obj().Pkg()) && typeArgsSpellable(t.TypeArgs())
+	case *types.Pointer:
+		return typeSpellable(t.Elem(), pkg)
+	case *types.Slice:
+		return typeSpellable(t.Elem(), pkg)
+	case *types.Array:
+		return typeSpellable(t.Elem(), pkg)
+	case *types.Chan:
+		return typeSpellable(t.Elem(), pkg)
+	case *types.Map:
+		return typeSpellable(t.Key(), pkg) && typeSpellable(t.Elem(), pkg)
+	case *types.Signature:
+		return tupleSpellable(t.Params()) && tupleSpellable(t.Results())
+	case *types.Struct:
+		for i := 0; i < t.NumFields(); i++ {
+			field := t.Field(i)
+			if !accessible(field.Exported(), field.Pkg()) || !typeSpellable(field.Type(), pkg) {
+				return false
+			}
+		}
+	case *types.Interface:
+		for i := 0; i < t.NumEmbeddeds(); i++ {
+			if !typeSpellable(t.EmbeddedType(i), pkg) {
+				return false
+			}
+		}
+		for i := 0; i < t.NumExplicitMethods(); i++ {
+			method := t.ExplicitMethod(i)
+			if !accessible(method.Exported(), method.Pkg()) || !typeSpellable(method.Type(), pkg) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// stubFuncLit returns a function literal with the signature that returns the zero value of
+// each result. Parameter names are kept when the signature declares them.
+func stubFuncLit(sig *types.Signature, pkg *packages.Package, state *fileState) dst.Expr {
+	params := tupleToFieldList(sig.Params(), sig.Variadic(), pkg, state)
+	for i, field := range params.List {
+		if name := sig.Params().At(i).Name(); name != "" {
+			field.Names = []*dst.Ident{{Name: name}}
+		}
+	}
+
+	body := &dst.BlockStmt{}
+	if sig.Results().Len() > 0 {
+		ret := &dst.ReturnStmt{}
+		for i := 0; i < sig.Results().Len(); i++ {
+			// Results are plain zero values, regardless of the options for fields
+			ret.Results = append(ret.Results, generateZeroValue(sig.Results().At(i).Type(), pkg, &Option{}, state))
+		}
+		body.List = append(body.List, ret)
+	}
+
+	return &dst.FuncLit{
+		Type: &dst.FuncType{
+			Func:    true,
+			Params:  params,
+			Results: tupleToFieldList(sig.Results(), false, pkg, state),
+		},
+		Body: body,
+	}
+}
+
+// tupleToFieldList converts function parameters or results to a field list without names
+func tupleToFieldList(tuple *types.Tuple, variadic bool, pkg *packages.Package, state *fileState) *dst.FieldList {
+	list := &dst.FieldList{}
+	for i := 0; i < tuple.Len(); i++ {
+		typ := typeToExpr(tuple.At(i).Type(), pkg, state)
+		if variadic && i == tuple.Len()-1 {
+			typ = &dst.Ellipsis{Elt: typeToExpr(tuple.At(i).Type().(*types.Slice).Elem(), pkg, state)}
+		}
+		list.List = append(list.List, &dst.Field{Type: typ})
+	}
+	return list
+}
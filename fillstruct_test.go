@@ -1,11 +1,28 @@
 package fillstruct
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
 	"testing"
 
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"golang.org/x/tools/go/packages"
 )
 
@@ -33,6 +50,20 @@ func TestFormat(t *testing.T) {
 		return fmt.Sprintf("%s/%s", testdataDir, s)
 	}
 
+	wildcardTypes, err := ResolveTargetTypes([]string{"github.com/nametake/fillstruct/testdata/wildcard_types/models.*"}, ".")
+	if err != nil {
+		t.Fatalf("ResolveTargetTypes returned unexpected error: %v", err)
+	}
+	orderTypes, err := ResolveTargetTypes([]string{"github.com/nametake/fillstruct/testdata/wildcard_types/models.Order"}, ".")
+	if err != nil {
+		t.Fatalf("ResolveTargetTypes returned unexpected error: %v", err)
+	}
+
+	const (
+		moneyPath  = "github.com/nametake/fillstruct/testdata/type_generator/money"
+		configPath = "github.com/nametake/fillstruct/testdata/same_base_name/alpha/config"
+	)
+
 	tests := []struct {
 		name       string
 		filePath   string
@@ -74,7 +105,7 @@ func TestFormat(t *testing.T) {
 			},
 		},
 		{
-			name:       "nested struct field is filled with empty composite literal",
+			name:       "nested struct field is filled with a literal of its type, filled as well",
 			filePath:   "nested_struct/input.go",
 			goldenFile: "nested_struct/golden.go",
 			option:     &Option{},
@@ -84,6 +115,18 @@ func TestFormat(t *testing.T) {
 				Errors:  []*FormatError{},
 			},
 		},
+		{
+			name:       "target type of an added struct value is matched",
+			filePath:   "nested_struct/input.go",
+			goldenFile: "nested_struct/golden.go",
+			option:     &Option{TargetTypeNames: []string{"Person", "Address"}},
+			want: &FormatResult{
+				Path:           addDirPrefix("nested_struct/input.go"),
+				Changed:        true,
+				Errors:         []*FormatError{},
+				MatchedTargets: []string{"Address", "Person"},
+			},
+		},
 		{
 			name:       "unexported field is not added",
 			filePath:   "unexported_field/input.go",
@@ -180,6 +223,17 @@ func TestFormat(t *testing.T) {
 				Errors:  []*FormatError{},
 			},
 		},
+		{
+			name:       "zero-valued enum constants are found without custom default values",
+			filePath:   "custom_default/input.go",
+			goldenFile: "custom_default/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("custom_default/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
 		{
 			name:       "custom default and zero values are mixed correctly",
 			filePath:   "custom_default_mixed/input.go",
@@ -232,46 +286,2067 @@ func TestFormat(t *testing.T) {
 				Errors:  []*FormatError{},
 			},
 		},
-	}
-
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			if test.want.Changed {
-				golden, err := os.ReadFile(test.goldenFile)
-				if err != nil {
-					t.Errorf("failed to read golden file %q: %v", test.goldenFile, err)
-				}
-				test.want.Output = golden
-			}
-
-			cfg := &packages.Config{
-				Mode:  packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo | packages.NeedFiles,
-				Tests: true,
-			}
-			pkgs, err := packages.Load(cfg, test.filePath)
-			if err != nil {
-				t.Errorf("failed to load packages: path = %s: %v", test.filePath, err)
-			}
-			if len(pkgs) != 1 {
-				t.Errorf("expected exactly one package: %s", test.filePath)
-			}
-
-			pkg := pkgs[0]
-
-			if len(pkg.Syntax) != 1 {
-				t.Errorf("expected exactly one file: %s", test.filePath)
-			}
-
-			file := pkg.Syntax[0]
-
-			got, err := Format(pkg, file, test.option)
-			if err != nil {
-				t.Errorf("Format(%q) returned unexpected error: %v", test.filePath, err)
-				return
-			}
-
-			if diff := cmp.Diff(test.want, got); diff != "" {
-				t.Errorf("Format(%q) returned unexpected result (-want +got):\n%s", test.filePath, diff)
+		{
+			name:       "external package enum with unqualified constant name",
+			filePath:   "external_enum/input.go",
+			goldenFile: "external_enum/golden.go",
+			option: &Option{
+				CustomDefaults: map[string]string{
+					"github.com/nametake/fillstruct/testdata/external_enum/otherpkg.Status": "StatusUnknown",
+				},
+			},
+			want: &FormatResult{
+				Path:    addDirPrefix("external_enum/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "external package enum constant is imported when the file does not import its package",
+			filePath:   "external_enum_import/input.go",
+			goldenFile: "external_enum_import/golden.go",
+			option: &Option{
+				CustomDefaults: map[string]string{
+					"github.com/nametake/fillstruct/testdata/external_enum/otherpkg.Status": "StatusUnknown",
+				},
+			},
+			want: &FormatResult{
+				Path:    addDirPrefix("external_enum_import/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "elements of array of struct are filled individually",
+			filePath:   "array_of_struct/input.go",
+			goldenFile: "array_of_struct/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("array_of_struct/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "anonymous struct literal is filled by default",
+			filePath:   "anonymous_struct/input.go",
+			goldenFile: "anonymous_struct/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("anonymous_struct/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "anonymous struct and interface types are written out in added values",
+			filePath:   "anonymous_type_expr/input.go",
+			goldenFile: "anonymous_type_expr/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("anonymous_type_expr/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "types with unexported fields of another package, as type arguments too, get the placeholder",
+			filePath:   "anonymous_type_expr/input_unexported.go",
+			goldenFile: "anonymous_type_expr/golden_unexported.go",
+			option:     &Option{UnknownPlaceholder: "FILLSTRUCT_TODO"},
+			want: &FormatResult{
+				Path:    addDirPrefix("anonymous_type_expr/input_unexported.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+				Warnings: []*FormatError{
+					{
+						Message: "field Items has unsupported type [1]struct{id int}, filled with placeholder FILLSTRUCT_TODO",
+						PosText: addDirPrefix("anonymous_type_expr/input_unexported.go") + ":6:6",
+						Position: token.Position{
+							Filename: addDirPrefix("anonymous_type_expr/input_unexported.go"),
+							Offset:   133,
+							Line:     6,
+							Column:   6,
+						},
+					},
+					{
+						Message: "field Result has unsupported type github.com/nametake/fillstruct/testdata/anonymous_type_expr/otherpkg.Response[struct{id int}], filled with placeholder FILLSTRUCT_TODO",
+						PosText: addDirPrefix("anonymous_type_expr/input_unexported.go") + ":6:6",
+						Position: token.Position{
+							Filename: addDirPrefix("anonymous_type_expr/input_unexported.go"),
+							Offset:   133,
+							Line:     6,
+							Column:   6,
+						},
+					},
+				},
+			},
+		},
+		{
+			name:       "anonymous struct literal is skipped with SkipAnonymous",
+			filePath:   "anonymous_struct_skip/input.go",
+			goldenFile: "anonymous_struct_skip/golden.go",
+			option:     &Option{SkipAnonymous: true},
+			want: &FormatResult{
+				Path:    addDirPrefix("anonymous_struct_skip/input.go"),
+				Changed: false,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "bare target type name is resolved against the formatted package",
+			filePath:   "bare_type_name/input.go",
+			goldenFile: "bare_type_name/golden.go",
+			option:     &Option{TargetTypeNames: []string{"User"}},
+			want: &FormatResult{
+				Path:           addDirPrefix("bare_type_name/input.go"),
+				Changed:        true,
+				Errors:         []*FormatError{},
+				MatchedTargets: []string{"User"},
+			},
+		},
+		{
+			name:       "zero valued enum constants are used for named basic types",
+			filePath:   "enum_constant/input.go",
+			goldenFile: "enum_constant/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("enum_constant/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "function body literals are skipped when TopLevelOnly is set",
+			filePath:   "top_level_only/input.go",
+			goldenFile: "top_level_only/golden.go",
+			option:     &Option{TopLevelOnly: true},
+			want: &FormatResult{
+				Path:    addDirPrefix("top_level_only/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "build constraint header is preserved",
+			filePath:   "build_tag/input.go",
+			goldenFile: "build_tag/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("build_tag/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "present fields with zero values are preserved verbatim",
+			filePath:   "present_zero_value/input.go",
+			goldenFile: "present_zero_value/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("present_zero_value/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "generic fields are rendered with their type arguments",
+			filePath:   "generic_pair/input.go",
+			goldenFile: "generic_pair/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("generic_pair/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "fields with unrelated or malformed struct tags are filled",
+			filePath:   "struct_tags/input.go",
+			goldenFile: "struct_tags/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("struct_tags/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "existing fields stay in place and missing fields are appended in struct order",
+			filePath:   "append_sorted/input.go",
+			goldenFile: "append_sorted/golden.go",
+			option:     &Option{FieldOrder: AppendSorted},
+			want: &FormatResult{
+				Path:    addDirPrefix("append_sorted/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "fields rejected by FieldFilter are not added",
+			filePath:   "field_filter/input.go",
+			goldenFile: "field_filter/golden.go",
+			option: &Option{
+				// Exclude all time.Time fields
+				FieldFilter: func(field *types.Var, named *types.Named) bool {
+					n, ok := field.Type().(*types.Named)
+					return !ok || n.Obj().Pkg() == nil || n.Obj().Pkg().Path() != "time" || n.Obj().Name() != "Time"
+				},
+			},
+			want: &FormatResult{
+				Path:    addDirPrefix("field_filter/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "generated file is skipped",
+			filePath:   "generated_file/input.go",
+			goldenFile: "generated_file/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("generated_file/input.go"),
+				Changed: false,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "generated file is filled when IncludeGenerated is set",
+			filePath:   "generated_include/input.go",
+			goldenFile: "generated_include/golden.go",
+			option:     &Option{IncludeGenerated: true},
+			want: &FormatResult{
+				Path:    addDirPrefix("generated_include/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "only marked regions of a generated file are filled",
+			filePath:   "generated_regions/input.go",
+			goldenFile: "generated_regions/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("generated_regions/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "literal type drives filling in interface-typed contexts",
+			filePath:   "interface_context/input.go",
+			goldenFile: "interface_context/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("interface_context/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "imports referenced by generated values are added",
+			filePath:   "auto_import/input.go",
+			goldenFile: "auto_import/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("auto_import/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "unsupported field types are filled with the placeholder and reported",
+			filePath:   "unknown_placeholder/input.go",
+			goldenFile: "unknown_placeholder/golden.go",
+			option:     &Option{UnknownPlaceholder: "FILLSTRUCT_TODO"},
+			want: &FormatResult{
+				Path:    addDirPrefix("unknown_placeholder/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+				Warnings: []*FormatError{
+					{
+						Message: "field Value has unsupported type T, filled with placeholder FILLSTRUCT_TODO",
+						PosText: addDirPrefix("unknown_placeholder/input.go") + ":9:9",
+						Position: token.Position{
+							Filename: addDirPrefix("unknown_placeholder/input.go"),
+							Offset:   118,
+							Line:     9,
+							Column:   9,
+						},
+					},
+				},
+			},
+		},
+		{
+			name:       "literals in a grouped var block are filled independently",
+			filePath:   "grouped_var/input.go",
+			goldenFile: "grouped_var/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("grouped_var/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "fields matching ExcludeFieldRegexp are not added",
+			filePath:   "exclude_field_regexp/input.go",
+			goldenFile: "exclude_field_regexp/golden.go",
+			option:     &Option{ExcludeFieldRegexp: regexp.MustCompile("^XXX_")},
+			want: &FormatResult{
+				Path:    addDirPrefix("exclude_field_regexp/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "qualified generic type arguments from other packages are imported",
+			filePath:   "generic_external/input.go",
+			goldenFile: "generic_external/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("generic_external/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "only anonymous structs matching a shape are filled",
+			filePath:   "anonymous_shape/input.go",
+			goldenFile: "anonymous_shape/golden.go",
+			option: &Option{
+				MatchUnnamedByShape: []*types.Struct{
+					types.NewStruct([]*types.Var{
+						types.NewField(token.NoPos, nil, "Host", types.Typ[types.String], false),
+						types.NewField(token.NoPos, nil, "Port", types.Typ[types.Int], false),
+					}, nil),
+				},
+			},
+			want: &FormatResult{
+				Path:    addDirPrefix("anonymous_shape/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "float fields are filled with zero",
+			filePath:   "float_fields/input.go",
+			goldenFile: "float_fields/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("float_fields/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "custom float defaults are used for float fields",
+			filePath:   "float_default/input.go",
+			goldenFile: "float_default/golden.go",
+			option: &Option{
+				CustomDefaults: map[string]string{
+					"float64": "1.5",
+					"float32": "0.5",
+				},
+			},
+			want: &FormatResult{
+				Path:    addDirPrefix("float_default/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "elided element literals in a returned slice are filled",
+			filePath:   "return_slice/input.go",
+			goldenFile: "return_slice/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("return_slice/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "computed values are preserved",
+			filePath:   "computed_values/input.go",
+			goldenFile: "computed_values/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("computed_values/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "incomplete positional literal of a target type is reported",
+			filePath:   "position_based/input.go",
+			goldenFile: "position_based/golden.go",
+			option:     &Option{TargetTypeNames: []string{"Person"}},
+			want: &FormatResult{
+				Path:    addDirPrefix("position_based/input.go"),
+				Changed: false,
+				Errors:  []*FormatError{},
+				Warnings: []*FormatError{
+					{
+						Message: "positional literal of target type Person has missing fields and is not filled, use field names or -convert-positional to fill it",
+						PosText: addDirPrefix("position_based/input.go") + ":10:6",
+						Position: token.Position{
+							Filename: addDirPrefix("position_based/input.go"),
+							Offset:   110,
+							Line:     10,
+							Column:   6,
+						},
+					},
+				},
+				MatchedTargets: []string{"Person"},
+			},
+		},
+		{
+			name:       "nested literals at every level are filled in one pass",
+			filePath:   "deep_nested/input.go",
+			goldenFile: "deep_nested/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("deep_nested/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "fields are filled with defaults from their fillstruct tag",
+			filePath:   "tag_default/input.go",
+			goldenFile: "tag_default/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("tag_default/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+				Warnings: []*FormatError{
+					{
+						Message: "field Host: default Missing is not a package-level var or const in package tag_default, filled with zero value",
+						PosText: addDirPrefix("tag_default/input.go") + ":23:6",
+						Position: token.Position{
+							Filename: addDirPrefix("tag_default/input.go"),
+							Offset:   540,
+							Line:     23,
+							Column:   6,
+						},
+					},
+					{
+						Message: "field Limit: default DefaultTimeout of type time.Duration is not assignable to int, filled with zero value",
+						PosText: addDirPrefix("tag_default/input.go") + ":23:6",
+						Position: token.Position{
+							Filename: addDirPrefix("tag_default/input.go"),
+							Offset:   540,
+							Line:     23,
+							Column:   6,
+						},
+					},
+					// Reported for the otherpkg.Client value added to the literal
+					{
+						Message: "field Backoff: default defaultBackoff is not exported from package otherpkg, filled with zero value",
+						PosText: addDirPrefix("tag_default/input.go") + ":23:6",
+						Position: token.Position{
+							Filename: addDirPrefix("tag_default/input.go"),
+							Offset:   540,
+							Line:     23,
+							Column:   6,
+						},
+					},
+					{
+						Message: "field Backoff: default defaultBackoff is not exported from package otherpkg, filled with zero value",
+						PosText: addDirPrefix("tag_default/input.go") + ":24:6",
+						Position: token.Position{
+							Filename: addDirPrefix("tag_default/input.go"),
+							Offset:   554,
+							Line:     24,
+							Column:   6,
+						},
+					},
+				},
+			},
+		},
+		{
+			name:       "elided and explicit literal types are kept as written",
+			filePath:   "literal_elision/input.go",
+			goldenFile: "literal_elision/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("literal_elision/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "embedded fields are added by type name instead of ambiguous promoted fields",
+			filePath:   "embedded_ambiguous/input.go",
+			goldenFile: "embedded_ambiguous/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("embedded_ambiguous/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "target type with only unexported fields is reported",
+			filePath:   "unexported_only/input.go",
+			goldenFile: "unexported_only/golden.go",
+			option:     &Option{TargetTypeNames: []string{"counter"}},
+			want: &FormatResult{
+				Path:    addDirPrefix("unexported_only/input.go"),
+				Changed: false,
+				Errors:  []*FormatError{},
+				Warnings: []*FormatError{
+					{
+						Message: "target type counter has no exported fields to fill",
+						PosText: addDirPrefix("unexported_only/input.go") + ":9:6",
+						Position: token.Position{
+							Filename: addDirPrefix("unexported_only/input.go"),
+							Offset:   91,
+							Line:     9,
+							Column:   6,
+						},
+					},
+				},
+				MatchedTargets: []string{"counter"},
+			},
+		},
+		{
+			name:       "only the literal enclosing the position is filled",
+			filePath:   "cursor_position/input.go",
+			goldenFile: "cursor_position/golden.go",
+			option:     &Option{Position: token.Position{Line: 16, Column: 22}},
+			want: &FormatResult{
+				Path:    addDirPrefix("cursor_position/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "fields are seeded from the Default line of the type doc comment",
+			filePath:   "doc_defaults/input.go",
+			goldenFile: "doc_defaults/golden.go",
+			option:     &Option{DocCommentDefaults: true},
+			want: &FormatResult{
+				Path:    addDirPrefix("doc_defaults/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "positional and mixed literals are skipped without errors",
+			filePath:   "mixed_keyed/input.go",
+			goldenFile: "mixed_keyed/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("mixed_keyed/input.go"),
+				Changed: false,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "nil values are converted to the field type with TypedNil",
+			filePath:   "typed_nil/input.go",
+			goldenFile: "typed_nil/golden.go",
+			option:     &Option{TypedNil: true},
+			want: &FormatResult{
+				Path:    addDirPrefix("typed_nil/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "nil values are untyped by default",
+			filePath:   "typed_nil/input.go",
+			goldenFile: "typed_nil/golden_untyped.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("typed_nil/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "complete and incomplete literals of the same type",
+			filePath:   "literal_coverage/input.go",
+			goldenFile: "literal_coverage/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("literal_coverage/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "concrete literals passed to generic functions are filled",
+			filePath:   "generic_context/input.go",
+			goldenFile: "generic_context/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("generic_context/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "standalone comments stay with their field when reordering",
+			filePath:   "standalone_comments/input.go",
+			goldenFile: "standalone_comments/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("standalone_comments/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "standalone comments stay in place when appending",
+			filePath:   "standalone_comments/input.go",
+			goldenFile: "standalone_comments/golden_append_sorted.go",
+			option:     &Option{FieldOrder: AppendSorted},
+			want: &FormatResult{
+				Path:    addDirPrefix("standalone_comments/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "literal zeros are used for named basic types with FillZeroForNamedBasic",
+			filePath:   "enum_constant/input.go",
+			goldenFile: "enum_constant/golden_zero.go",
+			option:     &Option{FillZeroForNamedBasic: true},
+			want: &FormatResult{
+				Path:    addDirPrefix("enum_constant/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "added fields are annotated with their declaration with SourceRefComments",
+			filePath:   "source_ref/input.go",
+			goldenFile: "source_ref/golden.go",
+			option:     &Option{SourceRefComments: true},
+			want: &FormatResult{
+				Path:    addDirPrefix("source_ref/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "literals in example functions are filled and output comments kept",
+			filePath:   "example_func/input.go",
+			goldenFile: "example_func/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("example_func/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "instantiated generic types fill fields with their type arguments",
+			filePath:   "generic_instantiated/input.go",
+			goldenFile: "generic_instantiated/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("generic_instantiated/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "embedded fields are filled with the name of their type",
+			filePath:   "embedded_field/input.go",
+			goldenFile: "embedded_field/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("embedded_field/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "incomplete positional literals are converted to keyed form with ConvertPositional",
+			filePath:   "convert_positional/input.go",
+			goldenFile: "convert_positional/golden.go",
+			option:     &Option{ConvertPositional: true},
+			want: &FormatResult{
+				Path:    addDirPrefix("convert_positional/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "positional literals without fields to add are left unchanged with ConvertPositional",
+			filePath:   "convert_positional/input.go",
+			goldenFile: "convert_positional/golden.go",
+			option:     &Option{ConvertPositional: true, FieldExclude: []string{"Age", "Email"}},
+			want: &FormatResult{
+				Path:    addDirPrefix("convert_positional/input.go"),
+				Changed: false,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "array zero values qualify and import external element types",
+			filePath:   "array_external/input.go",
+			goldenFile: "array_external/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("array_external/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "generated values use existing import aliases and ignore blank imports",
+			filePath:   "import_alias/input.go",
+			goldenFile: "import_alias/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("import_alias/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "packages whose name is taken by another import are imported under an alias",
+			filePath:   "import_conflict/input.go",
+			goldenFile: "import_conflict/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("import_conflict/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "named slice, map, channel and func types are filled with nil",
+			filePath:   "named_collections/input.go",
+			goldenFile: "named_collections/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("named_collections/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "named slice and map types are filled with empty literals with EmptyNamedCollections",
+			filePath:   "named_collections/input.go",
+			goldenFile: "named_collections/golden_empty.go",
+			option:     &Option{EmptyNamedCollections: true},
+			want: &FormatResult{
+				Path:    addDirPrefix("named_collections/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "comments of existing fields move with them when reordering",
+			filePath:   "reorder_comments/input.go",
+			goldenFile: "reorder_comments/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("reorder_comments/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "comments of existing fields are kept when appending sorted",
+			filePath:   "reorder_comments/input.go",
+			goldenFile: "reorder_comments/golden_append_sorted.go",
+			option:     &Option{FieldOrder: AppendSorted},
+			want: &FormatResult{
+				Path:    addDirPrefix("reorder_comments/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "existing fields stay in place with PreserveOrder",
+			filePath:   "append_sorted/input.go",
+			goldenFile: "append_sorted/golden.go",
+			option:     &Option{PreserveOrder: true},
+			want: &FormatResult{
+				Path:    addDirPrefix("append_sorted/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "struct values of missing fields are filled recursively with Recursive",
+			filePath:   "recursive/input.go",
+			goldenFile: "recursive/golden.go",
+			option:     &Option{Recursive: true},
+			want: &FormatResult{
+				Path:    addDirPrefix("recursive/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "recursive filling stops at RecursiveDepth",
+			filePath:   "recursive/input.go",
+			goldenFile: "recursive/golden_depth.go",
+			option:     &Option{Recursive: true, RecursiveDepth: 1},
+			want: &FormatResult{
+				Path:    addDirPrefix("recursive/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       `fields tagged with fillstruct:"-" are not filled`,
+			filePath:   "tag_skip/input.go",
+			goldenFile: "tag_skip/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("tag_skip/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "field directives are read from TagKey",
+			filePath:   "tag_skip/input.go",
+			goldenFile: "tag_skip/golden_tag_key.go",
+			option:     &Option{TagKey: "fill"},
+			want: &FormatResult{
+				Path:    addDirPrefix("tag_skip/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "unexported fields of the same package are filled with IncludeUnexported",
+			filePath:   "include_unexported/input.go",
+			goldenFile: "include_unexported/golden.go",
+			option:     &Option{IncludeUnexported: true},
+			want: &FormatResult{
+				Path:    addDirPrefix("include_unexported/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "target type with only unexported fields is filled with IncludeUnexported",
+			filePath:   "unexported_only/input.go",
+			goldenFile: "unexported_only/golden_include.go",
+			option:     &Option{IncludeUnexported: true, TargetTypeNames: []string{"counter"}},
+			want: &FormatResult{
+				Path:           addDirPrefix("unexported_only/input.go"),
+				Changed:        true,
+				Errors:         []*FormatError{},
+				MatchedTargets: []string{"counter"},
+			},
+		},
+		{
+			name:       "sample values are guessed from field names with SampleValues",
+			filePath:   "sample_values/input.go",
+			goldenFile: "sample_values/golden.go",
+			option:     &Option{SampleValues: true},
+			want: &FormatResult{
+				Path:    addDirPrefix("sample_values/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "interface defaults replace nil for configured interfaces",
+			filePath:   "interface_defaults/input.go",
+			goldenFile: "interface_defaults/golden.go",
+			option:     &Option{InterfaceDefaults: map[string]string{"io.Writer": "io.Discard", "io.Reader": "not valid("}},
+			want: &FormatResult{
+				Path:    addDirPrefix("interface_defaults/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "stub funcs fill function fields with function literals",
+			filePath:   "stub_funcs/input.go",
+			goldenFile: "stub_funcs/golden.go",
+			option:     &Option{StubFuncs: true},
+			want: &FormatResult{
+				Path:    addDirPrefix("stub_funcs/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "element literals of nested slices, arrays of pointers and anonymous structs",
+			filePath:   "nested_elements/input.go",
+			goldenFile: "nested_elements/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("nested_elements/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "struct literals as map values and keys",
+			filePath:   "map_struct_literals/input.go",
+			goldenFile: "map_struct_literals/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("map_struct_literals/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "include anonymous fills anonymous structs along with target types",
+			filePath:   "include_anonymous/input.go",
+			goldenFile: "include_anonymous/golden.go",
+			option:     &Option{TargetTypeNames: []string{"Config"}, IncludeAnonymous: true},
+			want: &FormatResult{
+				Path:           addDirPrefix("include_anonymous/input.go"),
+				Changed:        true,
+				Errors:         []*FormatError{},
+				MatchedTargets: []string{"Config"},
+			},
+		},
+		{
+			name:       "non-struct composite literals are never modified",
+			filePath:   "non_struct_literals/input.go",
+			goldenFile: "non_struct_literals/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("non_struct_literals/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "non-nil pointers point to empty named structs",
+			filePath:   "non_nil_pointers/input.go",
+			goldenFile: "non_nil_pointers/golden.go",
+			option:     &Option{NonNilPointers: true},
+			want: &FormatResult{
+				Path:    addDirPrefix("non_nil_pointers/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "byte slices, named byte slices and string aliases",
+			filePath:   "byte_slices/input.go",
+			goldenFile: "byte_slices/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("byte_slices/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "named byte slices with empty named collections",
+			filePath:   "byte_slices/input.go",
+			goldenFile: "byte_slices/golden_empty.go",
+			option:     &Option{EmptyNamedCollections: true},
+			want: &FormatResult{
+				Path:    addDirPrefix("byte_slices/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "field include and exclude patterns select the added fields",
+			filePath:   "field_patterns/input.go",
+			goldenFile: "field_patterns/golden.go",
+			option:     &Option{FieldInclude: []string{"*ID"}, FieldExclude: []string{"Internal*"}},
+			want: &FormatResult{
+				Path:    addDirPrefix("field_patterns/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "packages with the same name are told apart by import path",
+			filePath:   "same_base_name/input.go",
+			goldenFile: "same_base_name/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("same_base_name/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "hand-written nested literals of target types are filled",
+			filePath:   "nested_literal/input.go",
+			goldenFile: "nested_literal/golden.go",
+			option:     &Option{TargetTypeNames: []string{"Address"}},
+			want: &FormatResult{
+				Path:           addDirPrefix("nested_literal/input.go"),
+				Changed:        true,
+				Errors:         []*FormatError{},
+				MatchedTargets: []string{"Address"},
+			},
+		},
+		{
+			name:       "field patterns also apply to recursively filled values",
+			filePath:   "field_patterns_recursive/input.go",
+			goldenFile: "field_patterns_recursive/golden.go",
+			option:     &Option{TargetTypeNames: []string{"Order"}, Recursive: true, FieldExclude: []string{"Internal*"}},
+			want: &FormatResult{
+				Path:           addDirPrefix("field_patterns_recursive/input.go"),
+				Changed:        true,
+				Errors:         []*FormatError{},
+				MatchedTargets: []string{"Order"},
+			},
+		},
+		{
+			name:       "files with a line directive are filled and written to their own path",
+			filePath:   "line_directive/input.go",
+			goldenFile: "line_directive/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("line_directive/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "files reached through a symlinked directory are filled",
+			filePath:   "symlinked/input.go",
+			goldenFile: "simple/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("symlinked/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "type generators build the values of their types",
+			filePath:   "type_generator/input.go",
+			goldenFile: "type_generator/golden.go",
+			option: &Option{
+				TypeGenerators: map[string]func(t types.Type, pkg *packages.Package) (dst.Expr, []string){
+					moneyPath + ".Amount": func(t types.Type, pkg *packages.Package) (dst.Expr, []string) {
+						return &dst.CallExpr{
+							Fun: &dst.SelectorExpr{X: &dst.Ident{Name: "money"}, Sel: &dst.Ident{Name: "Zero"}},
+						}, []string{moneyPath}
+					},
+				},
+			},
+			want: &FormatResult{
+				Path:    addDirPrefix("type_generator/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			// The file already imports another package named config, so the generated
+			// config.Thing must be qualified with the alias of the added import
+			name:       "type generator imports are aliased on conflict",
+			filePath:   "same_base_name/input.go",
+			goldenFile: "same_base_name/golden_generator.go",
+			option: &Option{
+				TypeGenerators: map[string]func(t types.Type, pkg *packages.Package) (dst.Expr, []string){
+					configPath + ".Thing": func(t types.Type, pkg *packages.Package) (dst.Expr, []string) {
+						return &dst.CallExpr{
+							Fun: &dst.SelectorExpr{X: &dst.Ident{Name: "config"}, Sel: &dst.Ident{Name: "Default"}},
+						}, []string{configPath}
+					},
+				},
+			},
+			want: &FormatResult{
+				Path:    addDirPrefix("same_base_name/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "excluded types are skipped when targeted",
+			filePath:   "exclude_types/input.go",
+			goldenFile: "exclude_types/golden.go",
+			option:     &Option{TargetTypes: wildcardTypes, ExcludeTypes: orderTypes},
+			want: &FormatResult{
+				Path:           addDirPrefix("exclude_types/input.go"),
+				Changed:        true,
+				Errors:         []*FormatError{},
+				MatchedTargets: []string{"github.com/nametake/fillstruct/testdata/wildcard_types/models.User"},
+			},
+		},
+		{
+			name:       "excluded types are skipped when filling all literals",
+			filePath:   "exclude_types/input.go",
+			goldenFile: "exclude_types/golden.go",
+			option:     &Option{ExcludeTypes: orderTypes},
+			want: &FormatResult{
+				Path:    addDirPrefix("exclude_types/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "literals of aliases are filled",
+			filePath:   "alias_literal/input.go",
+			goldenFile: "alias_literal/golden.go",
+			option:     &Option{},
+			want: &FormatResult{
+				Path:    addDirPrefix("alias_literal/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "literals of aliases match the target type they denote",
+			filePath:   "alias_literal/input.go",
+			goldenFile: "alias_literal/golden_target.go",
+			option:     &Option{TargetTypeNames: []string{"Address"}},
+			want: &FormatResult{
+				Path:           addDirPrefix("alias_literal/input.go"),
+				Changed:        true,
+				Errors:         []*FormatError{},
+				MatchedTargets: []string{"Address"},
+			},
+		},
+		{
+			name:       "defaults apply to fields filled without Recursive",
+			filePath:   "recursive_defaults/input.go",
+			goldenFile: "recursive_defaults/golden.go",
+			option:     &Option{DocCommentDefaults: true},
+			want: &FormatResult{
+				Path:    addDirPrefix("recursive_defaults/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+		{
+			name:       "defaults apply to fields filled with Recursive",
+			filePath:   "recursive_defaults/input.go",
+			goldenFile: "recursive_defaults/golden.go",
+			option:     &Option{DocCommentDefaults: true, Recursive: true},
+			want: &FormatResult{
+				Path:    addDirPrefix("recursive_defaults/input.go"),
+				Changed: true,
+				Errors:  []*FormatError{},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.want.Changed {
+				golden, err := os.ReadFile(test.goldenFile)
+				if err != nil {
+					t.Errorf("failed to read golden file %q: %v", test.goldenFile, err)
+				}
+				test.want.Output = golden
+			}
+
+			pkg := loadTestPackage(t, ".", test.filePath)
+
+			if len(pkg.Syntax) != 1 {
+				t.Errorf("expected exactly one file: %s", test.filePath)
+			}
+
+			file := pkg.Syntax[0]
+
+			got, err := Format(pkg, file, test.option)
+			if err != nil {
+				t.Errorf("Format(%q) returned unexpected error: %v", test.filePath, err)
+				return
+			}
+
+			// Literals are covered by TestFormat_Literals
+			if diff := cmp.Diff(test.want, got, cmpopts.IgnoreFields(FormatResult{}, "Literals", "ImportEdits")); diff != "" {
+				t.Errorf("Format(%q) returned unexpected result (-want +got):\n%s", test.filePath, diff)
+			}
+
+			// Formatting the output again must not change it, e.g., for CI runs with -list
+			if got.Changed {
+				cfg := &packages.Config{
+					Mode:    testLoadMode,
+					Tests:   true,
+					Overlay: map[string][]byte{got.Path: got.Output},
+				}
+				pkgs, err := packages.Load(cfg, test.filePath)
+				if err != nil {
+					t.Fatalf("failed to reload packages: path = %s: %v", test.filePath, err)
+				}
+				again, err := Format(pkgs[0], pkgs[0].Syntax[0], test.option)
+				if err != nil {
+					t.Fatalf("Format(%q) of the output returned unexpected error: %v", test.filePath, err)
+				}
+				if again.Changed {
+					t.Errorf("Format(%q) is not idempotent (-first +second):\n%s", test.filePath, cmp.Diff(string(got.Output), string(again.Output)))
+				}
+			}
+		})
+	}
+}
+
+// testLoadMode loads the information Format needs
+const testLoadMode = packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo | packages.NeedFiles | packages.NeedImports | packages.NeedDeps
+
+// loadTestPackage loads the package of the files matched by patterns, relative to dir,
+// with Tests set so that patterns may name _test.go files
+func loadTestPackage(t testing.TB, dir string, patterns ...string) *packages.Package {
+	t.Helper()
+	cfg := &packages.Config{
+		Mode:  testLoadMode,
+		Dir:   dir,
+		Tests: true,
+	}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		t.Fatalf("failed to load packages: %v", err)
+	}
+	if len(pkgs) != 1 {
+		t.Fatalf("expected exactly one package for %v, got %d", patterns, len(pkgs))
+	}
+	return pkgs[0]
+}
+
+func TestFormat_DecorateError(t *testing.T) {
+
+	failingPath, err := filepath.Abs("testdata/simple/input.go")
+	if err != nil {
+		t.Fatalf("failed to get absolute path: %v", err)
+	}
+
+	// Simulate the decorator failing on a single file
+	original := decorateFile
+	decorateFile = func(dec *decorator.Decorator, file *ast.File) (*dst.File, error) {
+		if dec.Fset.Position(file.Pos()).Filename == failingPath {
+			return nil, fmt.Errorf("simulated failure")
+		}
+		return original(dec, file)
+	}
+	t.Cleanup(func() {
+		decorateFile = original
+	})
+
+	tests := []struct {
+		name        string
+		filePath    string
+		wantChanged bool
+		wantErrors  int
+	}{
+		{
+			name:        "decoration failure is recorded as a FormatError",
+			filePath:    "simple/input.go",
+			wantChanged: false,
+			wantErrors:  1,
+		},
+		{
+			name:        "other files are still processed",
+			filePath:    "pointer/input.go",
+			wantChanged: true,
+			wantErrors:  0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pkg := loadTestPackage(t, "testdata", test.filePath)
+
+			got, err := Format(pkg, pkg.Syntax[0], &Option{})
+			if err != nil {
+				t.Fatalf("Format(%q) returned unexpected error: %v", test.filePath, err)
+			}
+
+			if got.Changed != test.wantChanged {
+				t.Errorf("Format(%q).Changed = %v, want %v", test.filePath, got.Changed, test.wantChanged)
+			}
+			if len(got.Errors) != test.wantErrors {
+				t.Errorf("Format(%q) returned %d errors, want %d", test.filePath, len(got.Errors), test.wantErrors)
+			}
+		})
+	}
+}
+
+func TestResolveTargetTypes_Workspace(t *testing.T) {
+	t.Chdir("testdata/workspace/app")
+	// -mod=mod is rejected in workspace mode
+	t.Setenv("GOFLAGS", "-mod=readonly")
+
+	// The target type lives in a sibling module of the go.work workspace
+	targetTypes, err := ResolveTargetTypes([]string{"example.com/lib.Config"}, ".")
+	if err != nil {
+		t.Fatalf("ResolveTargetTypes returned unexpected error: %v", err)
+	}
+
+	golden, err := os.ReadFile("golden.go")
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+
+	pkg := loadTestPackage(t, ".", "input.go")
+
+	got, err := Format(pkg, pkg.Syntax[0], &Option{TargetTypes: targetTypes})
+	if err != nil {
+		t.Fatalf("Format returned unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(string(golden), string(got.Output)); diff != "" {
+		t.Errorf("Format returned unexpected output (-want +got):\n%s", diff)
+	}
+}
+
+func TestFormat_NormalizePathsVendored(t *testing.T) {
+	t.Chdir("testdata/vendored/app")
+	t.Setenv("GOFLAGS", "-mod=vendor")
+
+	// The module replaces example.com/lib with example.com/fork/lib and vendors it, so
+	// the literal has type example.com/lib.Config while the target is resolved from the fork
+	targetTypes, err := ResolveTargetTypes([]string{"example.com/fork/lib.Config"}, "../fork")
+	if err != nil {
+		t.Fatalf("ResolveTargetTypes returned unexpected error: %v", err)
+	}
+
+	golden, err := os.ReadFile("golden.go")
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+
+	pkg := loadTestPackage(t, ".", "input.go")
+
+	tests := []struct {
+		name    string
+		option  *Option
+		changed bool
+	}{
+		{
+			name:    "paths are compared as they are by default",
+			option:  &Option{TargetTypes: targetTypes},
+			changed: false,
+		},
+		{
+			name: "replaced module paths are normalized",
+			option: &Option{
+				TargetTypes:        targetTypes,
+				NormalizePaths:     true,
+				ModuleReplacements: map[string]string{"example.com/fork/lib": "example.com/lib"},
+			},
+			changed: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Format(pkg, pkg.Syntax[0], test.option)
+			if err != nil {
+				t.Fatalf("Format returned unexpected error: %v", err)
+			}
+			if got.Changed != test.changed {
+				t.Fatalf("Format returned Changed = %v, want %v", got.Changed, test.changed)
+			}
+			if !test.changed {
+				return
+			}
+			if diff := cmp.Diff(string(golden), string(got.Output)); diff != "" {
+				t.Errorf("Format returned unexpected output (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNormalizePath(t *testing.T) {
+	option := &Option{
+		NormalizePaths: true,
+		ModuleReplacements: map[string]string{
+			"example.com/fork/lib":     "example.com/lib",
+			"example.com/fork/lib/sub": "example.com/sub",
+		},
+	}
+
+	tests := []struct {
+		name    string
+		pkgPath string
+		option  *Option
+		want    string
+	}{
+		{
+			name:    "unchanged without NormalizePaths",
+			pkgPath: "example.com/app/vendor/example.com/lib",
+			option:  &Option{},
+			want:    "example.com/app/vendor/example.com/lib",
+		},
+		{
+			name:    "vendor prefix",
+			pkgPath: "example.com/app/vendor/example.com/lib",
+			option:  option,
+			want:    "example.com/lib",
+		},
+		{
+			name:    "top-level vendor directory",
+			pkgPath: "vendor/example.com/lib",
+			option:  option,
+			want:    "example.com/lib",
+		},
+		{
+			name:    "package of a replaced module",
+			pkgPath: "example.com/fork/lib/config",
+			option:  option,
+			want:    "example.com/lib/config",
+		},
+		{
+			name:    "longest replaced module",
+			pkgPath: "example.com/fork/lib/sub/config",
+			option:  option,
+			want:    "example.com/sub/config",
+		},
+		{
+			name:    "module sharing a prefix",
+			pkgPath: "example.com/fork/library",
+			option:  option,
+			want:    "example.com/fork/library",
+		},
+		{
+			name:    "vendored copy of a replaced module",
+			pkgPath: "example.com/app/vendor/example.com/fork/lib",
+			option:  option,
+			want:    "example.com/lib",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := normalizePath(test.pkgPath, test.option); got != test.want {
+				t.Errorf("normalizePath(%q) = %q, want %q", test.pkgPath, got, test.want)
+			}
+		})
+	}
+}
+
+// BenchmarkZeroConstant formats a file with many literals whose missing fields have enum
+// types, each filled with the constant holding the zero value of its type
+func BenchmarkZeroConstant(b *testing.B) {
+	const numConsts, numLiterals = 100, 500
+
+	var src strings.Builder
+	src.WriteString("package enums\n\ntype Status int\n\nconst (\n\tStatusUnknown Status = iota\n")
+	for i := 1; i < numConsts; i++ {
+		fmt.Fprintf(&src, "\tStatus%d\n", i)
+	}
+	src.WriteString(")\n\ntype Level string\n\nconst (\n\tLevelDefault Level = \"\"\n")
+	for i := 1; i < numConsts; i++ {
+		fmt.Fprintf(&src, "\tLevel%d Level = \"level%d\"\n", i, i)
+	}
+	src.WriteString(")\n\ntype Task struct {\n\tName   string\n\tStatus Status\n\tLevel  Level\n}\n\nfunc tasks() []Task {\n\treturn []Task{\n")
+	for i := 0; i < numLiterals; i++ {
+		fmt.Fprintf(&src, "\t\t{Name: \"task%d\"},\n", i)
+	}
+	src.WriteString("\t}\n}\n")
+
+	dir := b.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/enums\n\ngo 1.25.5\n"), 0644); err != nil {
+		b.Fatalf("failed to write go.mod: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "enums.go"), []byte(src.String()), 0644); err != nil {
+		b.Fatalf("failed to write file: %v", err)
+	}
+	pkg := loadTestPackage(b, dir, ".")
+
+	b.ReportAllocs()
+	for b.Loop() {
+		result, err := Format(pkg, pkg.Syntax[0], &Option{})
+		if err != nil {
+			b.Fatalf("Format returned unexpected error: %v", err)
+		}
+		if !result.Changed {
+			b.Fatal("Format did not fill the literals")
+		}
+	}
+}
+
+// TestFormat_Concurrent formats the files of a package from many goroutines, as the command
+// does. Each file needs a different import, so run with -race to check that per-file state,
+// such as the imports collected for generated values, is not shared.
+func TestFormat_Concurrent(t *testing.T) {
+	files := []string{"event", "page", "counter"}
+	patterns := []string{"concurrent_imports/types.go"}
+	goldens := make(map[string]string)
+	for _, name := range files {
+		patterns = append(patterns, "concurrent_imports/"+name+".go")
+		golden, err := os.ReadFile("testdata/concurrent_imports/golden_" + name + ".go")
+		if err != nil {
+			t.Fatalf("failed to read golden file: %v", err)
+		}
+		path, err := filepath.Abs("testdata/concurrent_imports/" + name + ".go")
+		if err != nil {
+			t.Fatalf("failed to get absolute path: %v", err)
+		}
+		goldens[path] = string(golden)
+	}
+
+	pkg := loadTestPackage(t, "testdata", patterns...)
+	var syntax []*ast.File
+	for _, file := range pkg.Syntax {
+		if _, ok := goldens[pkg.Fset.Position(file.Pos()).Filename]; ok {
+			syntax = append(syntax, file)
+		}
+	}
+	if len(syntax) != len(files) {
+		t.Fatalf("got %d files to format, want %d", len(syntax), len(files))
+	}
+
+	const workers = 32
+	results := make([]*FormatResult, workers)
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = Format(pkg, syntax[i%len(syntax)], &Option{})
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < workers; i++ {
+		if errs[i] != nil {
+			t.Fatalf("Format returned unexpected error: %v", errs[i])
+		}
+		if diff := cmp.Diff(goldens[results[i].Path], string(results[i].Output)); diff != "" {
+			t.Errorf("Format(%q) returned unexpected output (-want +got):\n%s", results[i].Path, diff)
+		}
+	}
+}
+
+func TestFormatError_MarshalJSON(t *testing.T) {
+	pkg := loadTestPackage(t, "testdata", "unknown_placeholder/input.go")
+	result, err := Format(pkg, pkg.Syntax[0], &Option{UnknownPlaceholder: "FILLSTRUCT_TODO"})
+	if err != nil {
+		t.Fatalf("Format returned unexpected error: %v", err)
+	}
+	if len(result.Warnings) != 1 {
+		t.Fatalf("got %d warnings, want 1", len(result.Warnings))
+	}
+
+	got, err := json.Marshal(result.Warnings[0])
+	if err != nil {
+		t.Fatalf("json.Marshal returned unexpected error: %v", err)
+	}
+
+	path, err := filepath.Abs("testdata/unknown_placeholder/input.go")
+	if err != nil {
+		t.Fatalf("failed to get absolute path: %v", err)
+	}
+	want := `{"file":"` + path + `","position":{"filename":"` + path + `","offset":118,"line":9,"column":9},"message":"field Value has unsupported type T, filled with placeholder FILLSTRUCT_TODO"}`
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("json.Marshal returned unexpected JSON (-want +got):\n%s", diff)
+	}
+}
+
+func TestFormat_Cgo(t *testing.T) {
+	if _, err := exec.LookPath("gcc"); err != nil {
+		t.Skip("cgo toolchain is not available")
+	}
+
+	input, err := filepath.Abs("testdata/cgo_preamble/input.go")
+	if err != nil {
+		t.Fatalf("failed to get absolute path: %v", err)
+	}
+	golden, err := os.ReadFile("testdata/cgo_preamble/golden.go")
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+
+	pkg := loadTestPackage(t, "testdata", "cgo_preamble/input.go")
+
+	// Syntax holds the files translated by cgo, one of which maps back to the input
+	var got *FormatResult
+	for _, file := range pkg.Syntax {
+		result, err := Format(pkg, file, &Option{IncludeGenerated: true})
+		if err != nil {
+			t.Fatalf("Format returned unexpected error: %v", err)
+		}
+		if result.Changed {
+			t.Errorf("Format(%q) changed a file translated by cgo", result.Path)
+		}
+		if result.Path == input {
+			got = result
+		}
+	}
+	if got == nil {
+		t.Fatalf("no result for %q", input)
+	}
+
+	want := []*FormatError{
+		newFormatError(token.Position{Filename: input, Line: 1, Column: 1}, `skipping file translated by cgo: literals in files importing "C" are not filled`),
+	}
+	if diff := cmp.Diff(want, got.Warnings, cmpopts.IgnoreFields(token.Position{}, "Offset"), cmpopts.IgnoreFields(FormatError{}, "PosText")); diff != "" {
+		t.Errorf("Format returned unexpected warnings (-want +got):\n%s", diff)
+	}
+
+	// The preamble on disk is untouched
+	content, err := os.ReadFile(input)
+	if err != nil {
+		t.Fatalf("failed to read input file: %v", err)
+	}
+	if diff := cmp.Diff(string(golden), string(content)); diff != "" {
+		t.Errorf("input file was modified (-want +got):\n%s", diff)
+	}
+}
+
+// TestGoldenCompiles type-checks golden files whose generated values reference
+// other packages, so qualification and import insertion are verified end to end
+func TestGoldenCompiles(t *testing.T) {
+	goldenFiles := []string{
+		"auto_import/golden.go",
+		"anonymous_type_expr/golden.go",
+		"generic_pair/golden.go",
+		"generic_external/golden.go",
+		"embedded_ambiguous/golden.go",
+		"typed_nil/golden.go",
+		"generic_context/golden.go",
+		"source_ref/golden.go",
+		"type_generator/golden.go",
+		"external_enum_import/golden.go",
+		"generic_instantiated/golden.go",
+		"embedded_field/golden.go",
+		"array_external/golden.go",
+		"import_alias/golden.go",
+		"import_conflict/golden.go",
+		"named_collections/golden.go",
+		"named_collections/golden_empty.go",
+		"recursive/golden.go",
+		"sample_values/golden.go",
+		"interface_defaults/golden.go",
+		"stub_funcs/golden.go",
+		"nested_elements/golden.go",
+		"map_struct_literals/golden.go",
+		"include_anonymous/golden.go",
+		"non_struct_literals/golden.go",
+		"non_nil_pointers/golden.go",
+		"byte_slices/golden.go",
+		"byte_slices/golden_empty.go",
+		"field_patterns/golden.go",
+		"field_patterns_recursive/golden.go",
+		"same_base_name/golden.go",
+		"same_base_name/golden_generator.go",
+		"nested_literal/golden.go",
+		"literal_edits/golden_positional.go",
+		"alias_literal/golden.go",
+		"recursive_defaults/golden.go",
+	}
+
+	for _, goldenFile := range goldenFiles {
+		t.Run(goldenFile, func(t *testing.T) {
+			pkg := loadTestPackage(t, "testdata", goldenFile)
+			packages.Visit([]*packages.Package{pkg}, nil, func(pkg *packages.Package) {
+				for _, err := range pkg.Errors {
+					t.Errorf("%s does not compile: %v", goldenFile, err)
+				}
+			})
+		})
+	}
+}
+
+func TestGenerateZeroValue_LiteralKind(t *testing.T) {
+	tests := []struct {
+		name     string
+		t        types.Type
+		option   *Option
+		wantKind token.Token
+	}{
+		{
+			name:     "int zero is an INT literal",
+			t:        types.Typ[types.Int],
+			option:   &Option{},
+			wantKind: token.INT,
+		},
+		{
+			name:     "float zero is a FLOAT literal",
+			t:        types.Typ[types.Float64],
+			option:   &Option{},
+			wantKind: token.FLOAT,
+		},
+		{
+			name:     "custom float default is a FLOAT literal",
+			t:        types.Typ[types.Float32],
+			option:   &Option{CustomDefaults: map[string]string{"float32": "1.5"}},
+			wantKind: token.FLOAT,
+		},
+		{
+			name:     "custom int default is an INT literal",
+			t:        types.Typ[types.Int],
+			option:   &Option{CustomDefaults: map[string]string{"int": "8080"}},
+			wantKind: token.INT,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := generateZeroValue(test.t, nil, test.option, newFileState(&ast.File{}, nil))
+			lit, ok := got.(*dst.BasicLit)
+			if !ok {
+				t.Fatalf("generateZeroValue returned %T, want *dst.BasicLit", got)
+			}
+			if lit.Kind != test.wantKind {
+				t.Errorf("generateZeroValue returned literal of kind %v, want %v", lit.Kind, test.wantKind)
+			}
+		})
+	}
+}
+
+func TestFormat_SkipFinalFormat(t *testing.T) {
+	pkg := loadTestPackage(t, "testdata", "append_sorted/input.go")
+
+	formatted, err := Format(pkg, pkg.Syntax[0], &Option{})
+	if err != nil {
+		t.Fatalf("Format returned unexpected error: %v", err)
+	}
+	unformatted, err := Format(pkg, pkg.Syntax[0], &Option{SkipFinalFormat: true})
+	if err != nil {
+		t.Fatalf("Format returned unexpected error: %v", err)
+	}
+	if !unformatted.Changed {
+		t.Fatalf("Format with SkipFinalFormat did not change the file")
+	}
+
+	// Running gofmt on the raw output yields the regular output
+	got, err := format.Source(unformatted.Output)
+	if err != nil {
+		t.Fatalf("raw output is not valid Go: %v", err)
+	}
+	if diff := cmp.Diff(string(formatted.Output), string(got)); diff != "" {
+		t.Errorf("formatted raw output differs from regular output (-want +got):\n%s", diff)
+	}
+}
+
+func TestFormat_TypesInfoOverride(t *testing.T) {
+	golden, err := os.ReadFile("testdata/simple/golden.go")
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+
+	// Load without type information, which the caller computes itself
+	cfg := &packages.Config{
+		Mode: packages.NeedTypes | packages.NeedSyntax | packages.NeedFiles | packages.NeedImports | packages.NeedDeps,
+		Dir:  "testdata",
+	}
+	pkgs, err := packages.Load(cfg, "simple/input.go")
+	if err != nil {
+		t.Fatalf("failed to load packages: %v", err)
+	}
+	pkg := pkgs[0]
+	if pkg.TypesInfo != nil {
+		t.Fatalf("package was loaded with type information")
+	}
+
+	info := &types.Info{Types: make(map[ast.Expr]types.TypeAndValue)}
+	conf := &types.Config{Importer: importer.Default()}
+	if _, err := conf.Check(pkg.PkgPath, pkg.Fset, pkg.Syntax, info); err != nil {
+		t.Fatalf("failed to type check: %v", err)
+	}
+
+	got, err := Format(pkg, pkg.Syntax[0], &Option{TypesInfoOverride: info})
+	if err != nil {
+		t.Fatalf("Format returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(string(golden), string(got.Output)); diff != "" {
+		t.Errorf("output mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFormatFile(t *testing.T) {
+	golden, err := os.ReadFile("testdata/simple/golden.go")
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+
+	// Parse and type check the file without go/packages
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "testdata/simple/input.go", nil, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("failed to parse file: %v", err)
+	}
+	info := &types.Info{
+		Types: make(map[ast.Expr]types.TypeAndValue),
+		Defs:  make(map[*ast.Ident]types.Object),
+		Uses:  make(map[*ast.Ident]types.Object),
+	}
+	conf := &types.Config{Importer: importer.Default()}
+	if _, err := conf.Check("example.com/simple", fset, []*ast.File{file}, info); err != nil {
+		t.Fatalf("failed to type check: %v", err)
+	}
+
+	got, err := FormatFile(fset, file, info, &Option{TargetTypeNames: []string{"Person"}})
+	if err != nil {
+		t.Fatalf("FormatFile returned unexpected error: %v", err)
+	}
+	if !got.Changed {
+		t.Fatalf("FormatFile().Changed = false, want true")
+	}
+	if diff := cmp.Diff(string(golden), string(got.Output)); diff != "" {
+		t.Errorf("output mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"Person"}, got.MatchedTargets); diff != "" {
+		t.Errorf("MatchedTargets mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFormat_MultiFile(t *testing.T) {
+	golden, err := os.ReadFile("testdata/multi_file/golden.go")
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+
+	// The struct and its zero constant are declared in types.go, the literal in handlers.go
+	pkg := loadTestPackage(t, "testdata", "multi_file/types.go", "multi_file/handlers.go")
+	if len(pkg.Syntax) != 2 {
+		t.Fatalf("expected two files, got %d", len(pkg.Syntax))
+	}
+
+	results := make(map[string]*FormatResult)
+	for _, file := range pkg.Syntax {
+		result, err := Format(pkg, file, &Option{})
+		if err != nil {
+			t.Fatalf("Format returned unexpected error: %v", err)
+		}
+		results[filepath.Base(result.Path)] = result
+	}
+
+	if results["types.go"].Changed {
+		t.Errorf("types.go was changed:\n%s", results["types.go"].Output)
+	}
+	if diff := cmp.Diff(string(golden), string(results["handlers.go"].Output)); diff != "" {
+		t.Errorf("handlers.go output mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFormatContext_Canceled(t *testing.T) {
+	pkg := loadTestPackage(t, "testdata", "simple/input.go")
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	result, err := FormatContext(ctx, pkg, pkg.Syntax[0], &Option{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("FormatContext returned error %v, want %v", err, context.Canceled)
+	}
+	if result != nil {
+		t.Errorf("FormatContext returned a result for a canceled context: %+v", result)
+	}
+}
+
+func TestFormat_Literals(t *testing.T) {
+	pkg := loadTestPackage(t, "testdata", "literal_coverage/input.go")
+
+	got, err := Format(pkg, pkg.Syntax[0], &Option{})
+	if err != nil {
+		t.Fatalf("Format returned unexpected error: %v", err)
+	}
+
+	type literal struct {
+		Type    string
+		Line    int
+		Column  int
+		Missing []string
+	}
+	var lits []literal
+	for _, lit := range got.Literals {
+		if lit.Position.Filename != pkg.Fset.Position(pkg.Syntax[0].Pos()).Filename {
+			t.Errorf("literal at %v is reported in another file", lit.Position)
+		}
+		lits = append(lits, literal{Type: lit.Type, Line: lit.Position.Line, Column: lit.Position.Column, Missing: lit.Missing})
+	}
+	// The positional literal is not reported since it is not considered for filling.
+	// Positions are those of the literals in the input, where the fields are inserted.
+	want := []literal{
+		{Type: "command-line-arguments.Person", Line: 14, Column: 6},
+		{Type: "command-line-arguments.Person", Line: 15, Column: 6, Missing: []string{"Age"}},
+		{Type: "command-line-arguments.Team", Line: 16, Column: 6},
+		{Type: "command-line-arguments.Person", Line: 19, Column: 4},
+		{Type: "command-line-arguments.Person", Line: 20, Column: 4, Missing: []string{"Name"}},
+	}
+	if diff := cmp.Diff(want, lits); diff != "" {
+		t.Errorf("Literals mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFormat_Trace(t *testing.T) {
+	pkg := loadTestPackage(t, "testdata", "literal_coverage/input.go")
+
+	var got []string
+	option := &Option{
+		TargetTypeNames: []string{"Person"},
+		Trace: func(pos token.Position, message string) {
+			got = append(got, fmt.Sprintf("%d: %s", pos.Line, message))
+		},
+	}
+	if _, err := Format(pkg, pkg.Syntax[0], option); err != nil {
+		t.Fatalf("Format returned unexpected error: %v", err)
+	}
+
+	want := []string{
+		"14: Person literal is complete",
+		"15: Person literal filled: Age",
+		"16: Team literal skipped: not a target type",
+		"19: Person literal is complete",
+		"20: Person literal filled: Name",
+		"23: Person literal skipped: not all elements are keyed",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("traces mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestIsTargetType_SamePathCopy(t *testing.T) {
+	// newConfig declares example.com/lib.Config in a new package with the given fields,
+	// as separate loads or a vendored copy of the same path would
+	newConfig := func(fields ...string) *types.Named {
+		p := types.NewPackage("example.com/lib", "lib")
+		vars := make([]*types.Var, len(fields))
+		for i, name := range fields {
+			vars[i] = types.NewField(token.NoPos, p, name, types.Typ[types.String], false)
+		}
+		obj := types.NewTypeName(token.NoPos, p, "Config", nil)
+		return types.NewNamed(obj, types.NewStruct(vars, nil), nil)
+	}
+
+	target := newConfig("Name", "Host")
+	pkg := &packages.Package{Types: types.NewPackage("example.com/app", "app")}
+	option := &Option{TargetTypes: []*types.Named{target}}
+
+	tests := []struct {
+		name  string
+		named *types.Named
+		want  bool
+	}{
+		{
+			name:  "same type",
+			named: target,
+			want:  true,
+		},
+		{
+			name:  "same path and fields from another load",
+			named: newConfig("Name", "Host"),
+			want:  true,
+		},
+		{
+			name:  "same path with different fields",
+			named: newConfig("Name", "Host", "Port"),
+			want:  false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isTargetType(test.named, pkg, option); got != test.want {
+				t.Errorf("isTargetType() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFormat_SampleValueHook(t *testing.T) {
+	pkg := loadTestPackage(t, "testdata", "sample_values/input.go")
+
+	// The hook replaces the guesses, and fields it declines get their zero value
+	option := &Option{
+		SampleValues: true,
+		SampleValue: func(field *types.Var) (dst.Expr, bool) {
+			if field.Name() != "Notes" {
+				return nil, false
+			}
+			return &dst.BasicLit{Kind: token.STRING, Value: `"custom"`}, true
+		},
+	}
+	got, err := Format(pkg, pkg.Syntax[0], option)
+	if err != nil {
+		t.Fatalf("Format returned unexpected error: %v", err)
+	}
+	for _, want := range []string{`Notes:     "custom",`, `Email:     "",`, `ID:        0,`} {
+		if !strings.Contains(string(got.Output), want) {
+			t.Errorf("output does not contain %q:\n%s", want, got.Output)
+		}
+	}
+}
+
+func TestResolveTargetTypes_Wildcard(t *testing.T) {
+	targetTypes, err := ResolveTargetTypes([]string{
+		"github.com/nametake/fillstruct/testdata/wildcard_types/models.*",
+		"github.com/nametake/fillstruct/testdata/sample_values/models.User",
+	}, "testdata")
+	if err != nil {
+		t.Fatalf("ResolveTargetTypes returned unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, targetType := range targetTypes {
+		got = append(got, types.TypeString(targetType, nil))
+	}
+	want := []string{
+		"github.com/nametake/fillstruct/testdata/wildcard_types/models.Item",
+		"github.com/nametake/fillstruct/testdata/wildcard_types/models.Order",
+		"github.com/nametake/fillstruct/testdata/wildcard_types/models.Page[T any]",
+		"github.com/nametake/fillstruct/testdata/wildcard_types/models.User",
+		"github.com/nametake/fillstruct/testdata/sample_values/models.User",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("resolved types mismatch (-want +got):\n%s", diff)
+	}
+
+	// Packages without struct types are an error, as the pattern is likely wrong
+	if _, err := ResolveTargetTypes([]string{"github.com/nametake/fillstruct/testdata/external_enum/otherpkg.*"}, "testdata"); err == nil {
+		t.Errorf("ResolveTargetTypes returned no error for a package without struct types")
+	}
+}
+
+func TestFormat_SkipsDecorationWithoutCandidates(t *testing.T) {
+
+	// Count how often files are decorated
+	decorated := 0
+	original := decorateFile
+	decorateFile = func(dec *decorator.Decorator, file *ast.File) (*dst.File, error) {
+		decorated++
+		return original(dec, file)
+	}
+	t.Cleanup(func() {
+		decorateFile = original
+	})
+
+	pkg := loadTestPackage(t, "testdata", "simple/input.go")
+
+	tests := []struct {
+		name          string
+		option        *Option
+		wantDecorated int
+		wantChanged   bool
+	}{
+		{
+			name:          "file without a literal of a target type is not decorated",
+			option:        &Option{TargetTypeNames: []string{"Missing"}},
+			wantDecorated: 0,
+			wantChanged:   false,
+		},
+		{
+			name:          "file with a literal of an excluded type is not decorated",
+			option:        &Option{ExcludeTypes: []*types.Named{pkg.Types.Scope().Lookup("Person").Type().(*types.Named)}},
+			wantDecorated: 0,
+			wantChanged:   false,
+		},
+		{
+			name:          "file with a literal of a target type is decorated",
+			option:        &Option{TargetTypeNames: []string{"Person"}},
+			wantDecorated: 1,
+			wantChanged:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			decorated = 0
+			got, err := Format(pkg, pkg.Syntax[0], test.option)
+			if err != nil {
+				t.Fatalf("Format returned unexpected error: %v", err)
+			}
+			if decorated != test.wantDecorated {
+				t.Errorf("file was decorated %d times, want %d", decorated, test.wantDecorated)
+			}
+			if got.Changed != test.wantChanged {
+				t.Errorf("Format().Changed = %v, want %v", got.Changed, test.wantChanged)
 			}
 		})
 	}
@@ -3,9 +3,11 @@ module github.com/nametake/fillstruct
 go 1.25.5
 
 require (
-	github.com/dave/dst v0.27.3 // indirect
-	github.com/google/go-cmp v0.7.0 // indirect
-	golang.org/x/mod v0.31.0 // indirect
-	golang.org/x/sync v0.19.0 // indirect
-	golang.org/x/tools v0.40.0 // indirect
+	github.com/dave/dst v0.27.3
+	github.com/google/go-cmp v0.7.0
+	go.yaml.in/yaml/v3 v3.0.4
+	golang.org/x/mod v0.31.0
+	golang.org/x/tools v0.40.0
 )
+
+require golang.org/x/sync v0.19.0 // indirect
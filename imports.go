@@ -0,0 +1,134 @@
+package fillstruct
+
+import (
+	"go/ast"
+	"go/token"
+	"path"
+	"sort"
+	"strconv"
+
+	"github.com/dave/dst"
+)
+
+// addImports adds import specs for the given packages (import path -> package name)
+// that the file does not import yet, and returns the added import paths in order
+func addImports(file *dst.File, imports map[string]string) []string {
+	existing := make(map[string]bool)
+	for _, spec := range file.Imports {
+		// Blank and dot imports do not make the package name available
+		if spec.Name != nil && (spec.Name.Name == "_" || spec.Name.Name == ".") {
+			continue
+		}
+		if importPath, err := strconv.Unquote(spec.Path.Value); err == nil {
+			existing[importPath] = true
+		}
+	}
+
+	var paths []string
+	for importPath := range imports {
+		if !existing[importPath] {
+			paths = append(paths, importPath)
+		}
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+	sort.Strings(paths)
+
+	// Reuse the first import declaration, or create one after the package clause
+	var decl *dst.GenDecl
+	for _, d := range file.Decls {
+		if gen, ok := d.(*dst.GenDecl); ok && gen.Tok == token.IMPORT {
+			decl = gen
+			break
+		}
+	}
+	if decl == nil {
+		decl = &dst.GenDecl{Tok: token.IMPORT}
+		decl.Decs.Before = dst.EmptyLine
+		decl.Decs.After = dst.EmptyLine
+		file.Decls = append([]dst.Decl{decl}, file.Decls...)
+	}
+	if !decl.Lparen {
+		// A single import is followed by an empty line, which would split the new block
+		for _, spec := range decl.Specs {
+			spec.(*dst.ImportSpec).Decs.Before = dst.NewLine
+			spec.(*dst.ImportSpec).Decs.After = dst.NewLine
+		}
+	}
+
+	for _, importPath := range paths {
+		spec := &dst.ImportSpec{
+			Path: &dst.BasicLit{Kind: token.STRING, Value: strconv.Quote(importPath)},
+		}
+		spec.Decs.Before = dst.NewLine
+		spec.Decs.After = dst.NewLine
+		// Name the import explicitly when the package name differs from the last path element
+		if name := imports[importPath]; name != path.Base(importPath) {
+			spec.Name = &dst.Ident{Name: name}
+		}
+		decl.Specs = append(decl.Specs, spec)
+		file.Imports = append(file.Imports, spec)
+	}
+	if len(decl.Specs) > 1 {
+		decl.Lparen = true
+	}
+	return paths
+}
+
+// importEdits returns the edits adding the import paths to the input file as addImports
+// adds them: to the first import declaration, or to a new one after the package clause
+func importEdits(tokFile *token.File, file *ast.File, paths []string, imports map[string]string) []TextEdit {
+	if len(paths) == 0 {
+		return nil
+	}
+	specs := make([]string, len(paths))
+	for i, importPath := range paths {
+		specs[i] = strconv.Quote(importPath)
+		if name := imports[importPath]; name != path.Base(importPath) {
+			specs[i] = name + " " + specs[i]
+		}
+	}
+	insert := func(pos token.Pos, text string) TextEdit {
+		offset := tokFile.Offset(pos)
+		return TextEdit{Start: offset, End: offset, NewText: text}
+	}
+	line := func(pos token.Pos) int {
+		return tokFile.PositionFor(pos, false).Line
+	}
+
+	var decl *ast.GenDecl
+	for _, d := range file.Decls {
+		if gen, ok := d.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			decl = gen
+			break
+		}
+	}
+
+	var block string
+	for _, spec := range specs {
+		block += "\n\t" + spec
+	}
+	switch {
+	case decl == nil && len(specs) == 1:
+		return []TextEdit{insert(file.Name.End(), "\n\nimport "+specs[0])}
+	case decl == nil:
+		return []TextEdit{insert(file.Name.End(), "\n\nimport ("+block+"\n)")}
+	case !decl.Lparen.IsValid():
+		// A single import becomes a block
+		return []TextEdit{
+			insert(decl.Specs[0].Pos(), "(\n\t"),
+			insert(decl.Specs[0].End(), block+"\n)"),
+		}
+	}
+
+	last := decl.Lparen
+	if len(decl.Specs) > 0 {
+		last = decl.Specs[len(decl.Specs)-1].End()
+	}
+	if line(decl.Rparen) > line(last) {
+		// Add lines before the one of the closing parenthesis
+		return []TextEdit{insert(tokFile.LineStart(line(decl.Rparen)), block[1:]+"\n")}
+	}
+	return []TextEdit{insert(decl.Rparen, block+"\n")}
+}
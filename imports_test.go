@@ -0,0 +1,91 @@
+package fillstruct
+
+import (
+	"bytes"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/dave/dst/decorator"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAddImports(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		imports map[string]string
+		want    string
+	}{
+		{
+			name:    "import declaration is created when the file has none",
+			src:     "package p\n\nvar x = 1\n",
+			imports: map[string]string{"time": "time"},
+			want:    "package p\n\nimport \"time\"\n\nvar x = 1\n",
+		},
+		{
+			name:    "single import is converted to a block",
+			src:     "package p\n\nimport \"fmt\"\n\nvar x = fmt.Sprint()\n",
+			imports: map[string]string{"time": "time"},
+			want:    "package p\n\nimport (\n\t\"fmt\"\n\t\"time\"\n)\n\nvar x = fmt.Sprint()\n",
+		},
+		{
+			name:    "import block gets a line per package",
+			src:     "package p\n\nimport (\n\t\"fmt\"\n)\n\nvar x = fmt.Sprint()\n",
+			imports: map[string]string{"time": "time", "os": "os"},
+			want:    "package p\n\nimport (\n\t\"fmt\"\n\t\"os\"\n\t\"time\"\n)\n\nvar x = fmt.Sprint()\n",
+		},
+		{
+			name:    "already imported package is not duplicated",
+			src:     "package p\n\nimport \"time\"\n\nvar x time.Time\n",
+			imports: map[string]string{"time": "time"},
+			want:    "package p\n\nimport \"time\"\n\nvar x time.Time\n",
+		},
+		{
+			name:    "package name differing from the path is imported with a name",
+			src:     "package p\n\nvar x = 1\n",
+			imports: map[string]string{"gopkg.in/yaml.v3": "yaml"},
+			want:    "package p\n\nimport yaml \"gopkg.in/yaml.v3\"\n\nvar x = 1\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file, err := decorator.Parse(test.src)
+			if err != nil {
+				t.Fatalf("failed to parse source: %v", err)
+			}
+
+			paths := addImports(file, test.imports)
+
+			var buf bytes.Buffer
+			if err := decorator.Fprint(&buf, file); err != nil {
+				t.Fatalf("failed to print file: %v", err)
+			}
+			got, err := format.Source(buf.Bytes())
+			if err != nil {
+				t.Fatalf("failed to format source: %v", err)
+			}
+
+			if diff := cmp.Diff(test.want, string(got)); diff != "" {
+				t.Errorf("addImports returned unexpected source (-want +got):\n%s", diff)
+			}
+
+			// The edits of the input add the same imports
+			fset := token.NewFileSet()
+			astFile, err := parser.ParseFile(fset, "p.go", test.src, 0)
+			if err != nil {
+				t.Fatalf("failed to parse source: %v", err)
+			}
+			edits := importEdits(fset.File(astFile.Pos()), astFile, paths, test.imports)
+			applied := test.src
+			for i := len(edits) - 1; i >= 0; i-- {
+				applied = applied[:edits[i].Start] + edits[i].NewText + applied[edits[i].End:]
+			}
+			if diff := cmp.Diff(test.want, applied); diff != "" {
+				t.Errorf("importEdits returned unexpected edits (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
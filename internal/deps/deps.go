@@ -0,0 +1,27 @@
+// Package deps searches the import graph of type-checked packages
+package deps
+
+import "go/types"
+
+// Find returns the first package among p and its dependencies, depth first in import
+// order, for which match reports true, or nil if there is none
+func Find(p *types.Package, match func(*types.Package) bool) *types.Package {
+	seen := make(map[*types.Package]bool)
+	var find func(p *types.Package) *types.Package
+	find = func(p *types.Package) *types.Package {
+		if seen[p] {
+			return nil
+		}
+		seen[p] = true
+		if match(p) {
+			return p
+		}
+		for _, imp := range p.Imports() {
+			if found := find(imp); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	return find(p)
+}
@@ -0,0 +1,100 @@
+// Package diff computes line-based differences between texts
+package diff
+
+import "strings"
+
+// OpKind is the kind of an edit operation
+type OpKind int
+
+const (
+	Equal OpKind = iota
+	Delete
+	Insert
+)
+
+// Op is a line kept, deleted from the old text or inserted from the new text
+type Op struct {
+	Kind OpKind
+	Line string
+}
+
+// SplitLines splits s into lines, keeping the line terminators
+func SplitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Lines computes the shortest edit script turning a into b using Myers' algorithm
+func Lines(a, b []string) []Op {
+	n, m := len(a), len(b)
+	maxD := n + m
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	var trace [][]int
+
+	for d := 0; d <= maxD; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, a, b, offset, d)
+			}
+		}
+	}
+	return nil
+}
+
+// backtrack walks the trace of Lines back from the end to build the edit script
+func backtrack(trace [][]int, a, b []string, offset, d int) []Op {
+	x, y := len(a), len(b)
+	var ops []Op
+	for ; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, Op{Kind: Equal, Line: a[x]})
+		}
+		if x == prevX {
+			y--
+			ops = append(ops, Op{Kind: Insert, Line: b[y]})
+		} else {
+			x--
+			ops = append(ops, Op{Kind: Delete, Line: a[x]})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		ops = append(ops, Op{Kind: Equal, Line: a[x]})
+	}
+
+	// Reverse into forward order
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
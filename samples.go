@@ -0,0 +1,78 @@
+package fillstruct
+
+import (
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"github.com/dave/dst"
+)
+
+// sampleValue returns the sample value of the field for Option.SampleValues, or nil
+// when there is none
+func sampleValue(field *types.Var, opt *Option, state *fileState) dst.Expr {
+	if opt.SampleValue != nil {
+		if expr, ok := opt.SampleValue(field); ok {
+			return expr
+		}
+		return nil
+	}
+	return guessSampleValue(field, state)
+}
+
+// guessSampleValue guesses a sample value from the name and type of the field
+func guessSampleValue(field *types.Var, state *fileState) dst.Expr {
+	name := strings.ToLower(field.Name())
+
+	if named, ok := types.Unalias(field.Type()).(*types.Named); ok {
+		obj := named.Obj()
+		if obj.Pkg() != nil && obj.Pkg().Path() == "time" && obj.Name() == "Time" {
+			return &dst.CallExpr{
+				Fun: &dst.SelectorExpr{
+					X:   &dst.Ident{Name: state.qualifier(obj.Pkg())},
+					Sel: &dst.Ident{Name: "Now"},
+				},
+			}
+		}
+	}
+
+	// Named basic types (e.g., enums) are left to their zero values
+	basic, ok := types.Unalias(field.Type()).(*types.Basic)
+	if !ok {
+		return nil
+	}
+	switch {
+	case basic.Info()&types.IsString != 0:
+		switch {
+		case strings.Contains(name, "email"):
+			return sampleString("user@example.com")
+		case strings.HasSuffix(name, "url"), strings.HasSuffix(name, "uri"), strings.Contains(name, "website"):
+			return sampleString("https://example.com")
+		case strings.Contains(name, "phone"):
+			return sampleString("+1-555-0100")
+		case strings.HasSuffix(name, "name"), strings.HasSuffix(name, "title"):
+			return sampleString("example")
+		}
+	case basic.Info()&types.IsInteger != 0:
+		switch {
+		case name == "id" || strings.HasSuffix(field.Name(), "ID") || strings.HasSuffix(field.Name(), "Id"):
+			return &dst.BasicLit{Kind: token.INT, Value: "1"}
+		case name == "age":
+			return &dst.BasicLit{Kind: token.INT, Value: "30"}
+		case name == "port":
+			return &dst.BasicLit{Kind: token.INT, Value: "8080"}
+		case strings.Contains(name, "count"), strings.Contains(name, "quantity"):
+			return &dst.BasicLit{Kind: token.INT, Value: "1"}
+		}
+	case basic.Info()&types.IsBoolean != 0:
+		if strings.Contains(name, "enabled") || strings.Contains(name, "active") {
+			return &dst.Ident{Name: "true"}
+		}
+	}
+	return nil
+}
+
+func sampleString(value string) dst.Expr {
+	return &dst.BasicLit{Kind: token.STRING, Value: strconv.Quote(value)}
+}
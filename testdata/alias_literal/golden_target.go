@@ -0,0 +1,22 @@
+package alias_literal
+
+type Address struct {
+	City string
+	Zip  string
+}
+
+type Location = Address
+
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+type Entry[V any] = Pair[string, V]
+
+func main() {
+	_ = Location{City: "Tokyo", Zip: ""}
+	_ = &Location{City: "", Zip: "100"}
+	_ = []Location{{City: "Osaka", Zip: ""}}
+	_ = Entry[int]{Key: "a"}
+}
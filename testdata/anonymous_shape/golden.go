@@ -0,0 +1,16 @@
+package anonymous_shape
+
+var server = struct {
+	Host string
+	Port int
+}{
+	Host: "localhost",
+	Port: 0,
+}
+
+var user = struct {
+	Name  string
+	Admin bool
+}{
+	Name: "alice",
+}
@@ -0,0 +1,15 @@
+package anonymous_shape
+
+var server = struct {
+	Host string
+	Port int
+}{
+	Host: "localhost",
+}
+
+var user = struct {
+	Name  string
+	Admin bool
+}{
+	Name: "alice",
+}
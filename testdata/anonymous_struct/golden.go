@@ -0,0 +1,11 @@
+package anonymous_struct
+
+func main() {
+	_ = struct {
+		Name string
+		Age  int
+	}{
+		Name: "Alice",
+		Age:  0,
+	}
+}
@@ -0,0 +1,10 @@
+package anonymous_struct
+
+func main() {
+	_ = struct {
+		Name string
+		Age  int
+	}{
+		Name: "Alice",
+	}
+}
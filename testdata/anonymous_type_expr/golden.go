@@ -0,0 +1,48 @@
+package anonymous_type_expr
+
+import "github.com/nametake/fillstruct/testdata/anonymous_type_expr/otherpkg"
+
+type Box[T any] struct {
+	Value T
+}
+
+type Config struct {
+	Name   string
+	Points [2]struct{ X, Y int }
+	Meta   struct {
+		ID int `json:"id"`
+	}
+	Handlers [1]interface{ Handle(name string) error }
+	Box      Box[struct{ X int }]
+	Pair     Box[[1]interface{ Close() error }]
+	Response otherpkg.Response[struct{ ID int }]
+}
+
+func main() {
+	_ = Config{
+		Name: "config",
+		Points: [2]struct {
+			X int
+			Y int
+		}{},
+		Meta: struct {
+			ID int `json:"id"`
+		}{
+			ID: 0,
+		},
+		Handlers: [1]interface{ Handle(string) error }{},
+		Box: Box[struct{ X int }]{
+			Value: struct{ X int }{
+				X: 0,
+			},
+		},
+		Pair: Box[[1]interface{ Close() error }]{
+			Value: [1]interface{ Close() error }{},
+		},
+		Response: otherpkg.Response[struct{ ID int }]{
+			Data: struct{ ID int }{
+				ID: 0,
+			},
+		},
+	}
+}
@@ -0,0 +1,11 @@
+package anonymous_type_expr
+
+import "github.com/nametake/fillstruct/testdata/anonymous_type_expr/otherpkg"
+
+func unexported() {
+	_ = otherpkg.Wrapper{
+		Name:   "wrapper",
+		Items:  FILLSTRUCT_TODO,
+		Result: FILLSTRUCT_TODO,
+	}
+}
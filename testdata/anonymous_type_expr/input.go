@@ -0,0 +1,25 @@
+package anonymous_type_expr
+
+import "github.com/nametake/fillstruct/testdata/anonymous_type_expr/otherpkg"
+
+type Box[T any] struct {
+	Value T
+}
+
+type Config struct {
+	Name   string
+	Points [2]struct{ X, Y int }
+	Meta   struct {
+		ID int `json:"id"`
+	}
+	Handlers [1]interface{ Handle(name string) error }
+	Box      Box[struct{ X int }]
+	Pair     Box[[1]interface{ Close() error }]
+	Response otherpkg.Response[struct{ ID int }]
+}
+
+func main() {
+	_ = Config{
+		Name: "config",
+	}
+}
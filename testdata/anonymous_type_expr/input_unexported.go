@@ -0,0 +1,9 @@
+package anonymous_type_expr
+
+import "github.com/nametake/fillstruct/testdata/anonymous_type_expr/otherpkg"
+
+func unexported() {
+	_ = otherpkg.Wrapper{
+		Name: "wrapper",
+	}
+}
@@ -0,0 +1,11 @@
+package otherpkg
+
+type Wrapper struct {
+	Name   string
+	Items  [1]struct{ id int }
+	Result Response[struct{ id int }]
+}
+
+type Response[T any] struct {
+	Data T
+}
@@ -0,0 +1,20 @@
+package append_sorted
+
+type Server struct {
+	Host    string
+	Port    int
+	Timeout int
+	Debug   bool
+	Name    string
+}
+
+func main() {
+	_ = &Server{
+		Name: "api",
+		// listen address
+		Port:    8080,
+		Host:    "",
+		Timeout: 0,
+		Debug:   false,
+	}
+}
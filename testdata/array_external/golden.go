@@ -0,0 +1,17 @@
+package array_external
+
+import (
+	"bytes"
+	"github.com/nametake/fillstruct/testdata/array_external/models"
+	"time"
+)
+
+func main() {
+	_ = models.Schedule{
+		Name:     "weekly",
+		Slots:    [3]time.Time{},
+		Pointers: [2]*time.Location{},
+		Nested:   [2][]time.Duration{},
+		Buffers:  [1]map[string]bytes.Buffer{},
+	}
+}
@@ -0,0 +1,9 @@
+package array_external
+
+import "github.com/nametake/fillstruct/testdata/array_external/models"
+
+func main() {
+	_ = models.Schedule{
+		Name: "weekly",
+	}
+}
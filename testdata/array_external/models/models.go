@@ -0,0 +1,14 @@
+package models
+
+import (
+	"bytes"
+	"time"
+)
+
+type Schedule struct {
+	Name     string
+	Slots    [3]time.Time
+	Pointers [2]*time.Location
+	Nested   [2][]time.Duration
+	Buffers  [1]map[string]bytes.Buffer
+}
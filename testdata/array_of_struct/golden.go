@@ -0,0 +1,30 @@
+package array_of_struct
+
+type Config struct {
+	Name string
+	Port int
+}
+
+func main() {
+	_ = [2]Config{}
+	_ = [2]Config{
+		{
+			Name: "a",
+			Port: 0,
+		},
+		{
+			Name: "",
+			Port: 0,
+		},
+	}
+	_ = [3]Config{
+		0: {
+			Name: "b",
+			Port: 0,
+		},
+		2: {
+			Name: "",
+			Port: 8080,
+		},
+	}
+}
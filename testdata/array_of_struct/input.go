@@ -0,0 +1,24 @@
+package array_of_struct
+
+type Config struct {
+	Name string
+	Port int
+}
+
+func main() {
+	_ = [2]Config{}
+	_ = [2]Config{
+		{
+			Name: "a",
+		},
+		{},
+	}
+	_ = [3]Config{
+		0: {
+			Name: "b",
+		},
+		2: {
+			Port: 8080,
+		},
+	}
+}
@@ -0,0 +1,17 @@
+package auto_import
+
+import (
+	"github.com/nametake/fillstruct/testdata/auto_import/models"
+	"github.com/nametake/fillstruct/testdata/auto_import/owner"
+	"time"
+)
+
+func main() {
+	_ = &models.Event{
+		Name: "launch",
+		When: time.Time{},
+		Owner: owner.User{
+			Name: "",
+		},
+	}
+}
@@ -0,0 +1,9 @@
+package auto_import
+
+import "github.com/nametake/fillstruct/testdata/auto_import/models"
+
+func main() {
+	_ = &models.Event{
+		Name: "launch",
+	}
+}
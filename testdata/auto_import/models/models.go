@@ -0,0 +1,13 @@
+package models
+
+import (
+	"time"
+
+	"github.com/nametake/fillstruct/testdata/auto_import/owner"
+)
+
+type Event struct {
+	Name  string
+	When  time.Time
+	Owner owner.User
+}
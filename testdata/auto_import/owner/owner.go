@@ -0,0 +1,5 @@
+package owner
+
+type User struct {
+	Name string
+}
@@ -0,0 +1,20 @@
+package bare_type_name
+
+type User struct {
+	Name string
+	Age  int
+}
+
+type Group struct {
+	Name    string
+	Members []User
+}
+
+func main() {
+	_ = &User{
+		Name: "Alice",
+	}
+	_ = &Group{
+		Name: "admins",
+	}
+}
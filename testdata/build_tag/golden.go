@@ -0,0 +1,15 @@
+//go:build fixtures
+
+package build_tag
+
+type User struct {
+	Name  string
+	Email string
+}
+
+var Fixtures = []User{
+	{
+		Name:  "alice",
+		Email: "",
+	},
+}
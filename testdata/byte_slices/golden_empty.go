@@ -0,0 +1,29 @@
+package byte_slices
+
+import "encoding/json"
+
+type ID string
+
+type Name = string
+
+type Key []byte
+
+type Record struct {
+	ID      ID
+	Name    Name
+	Initial rune
+	Data    []byte
+	Payload json.RawMessage
+	Key     Key
+}
+
+func main() {
+	_ = Record{
+		ID:      "1",
+		Name:    "",
+		Initial: 0,
+		Data:    nil,
+		Payload: json.RawMessage{},
+		Key:     Key{},
+	}
+}
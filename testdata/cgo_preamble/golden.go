@@ -0,0 +1,23 @@
+package cgo_preamble
+
+// #include <stdlib.h>
+//
+// static int answer(void) {
+//     return 42;
+// }
+import "C"
+
+type Config struct {
+	Name  string
+	Value int
+}
+
+func Answer() int {
+	return int(C.answer())
+}
+
+func main() {
+	_ = &Config{
+		Name: "cgo",
+	}
+}
@@ -0,0 +1,27 @@
+package computed_values
+
+import "strings"
+
+type Config struct {
+	A     int
+	B     int
+	Name  string
+	Tags  []string
+	Ratio float64
+}
+
+func scale(v int) int {
+	return v * 10
+}
+
+func main() {
+	cfg := Config{A: 1, B: 0, Name: "", Tags: nil, Ratio: 0}
+	_ = Config{
+		A: scale(cfg.A) + len(cfg.Tags),
+		B: cfg.A * 2, // derived from A
+		// trimmed name
+		Name:  strings.TrimSpace(" x " + "y"),
+		Tags:  nil,
+		Ratio: 0,
+	}
+}
@@ -0,0 +1,25 @@
+package computed_values
+
+import "strings"
+
+type Config struct {
+	A     int
+	B     int
+	Name  string
+	Tags  []string
+	Ratio float64
+}
+
+func scale(v int) int {
+	return v * 10
+}
+
+func main() {
+	cfg := Config{A: 1}
+	_ = Config{
+		B: cfg.A * 2, // derived from A
+		// trimmed name
+		Name: strings.TrimSpace(" x " + "y"),
+		A:    scale(cfg.A) + len(cfg.Tags),
+	}
+}
@@ -0,0 +1,7 @@
+package concurrent_imports
+
+func newCounter() *Counter {
+	return &Counter{
+		Count: 1,
+	}
+}
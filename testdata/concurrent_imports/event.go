@@ -0,0 +1,7 @@
+package concurrent_imports
+
+func newEvent() Event {
+	return Event{
+		Name: "started",
+	}
+}
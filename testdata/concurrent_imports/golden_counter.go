@@ -0,0 +1,10 @@
+package concurrent_imports
+
+import "sync"
+
+func newCounter() *Counter {
+	return &Counter{
+		Count: 1,
+		Lock:  sync.Mutex{},
+	}
+}
@@ -0,0 +1,10 @@
+package concurrent_imports
+
+import "time"
+
+func newEvent() Event {
+	return Event{
+		Name: "started",
+		At:   time.Time{},
+	}
+}
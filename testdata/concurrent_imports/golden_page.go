@@ -0,0 +1,10 @@
+package concurrent_imports
+
+import "strings"
+
+func newPage() *Page {
+	return &Page{
+		Title: "home",
+		Body:  strings.Builder{},
+	}
+}
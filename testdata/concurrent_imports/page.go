@@ -0,0 +1,7 @@
+package concurrent_imports
+
+func newPage() *Page {
+	return &Page{
+		Title: "home",
+	}
+}
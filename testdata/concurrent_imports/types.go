@@ -0,0 +1,22 @@
+package concurrent_imports
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+type Event struct {
+	Name string
+	At   time.Time
+}
+
+type Page struct {
+	Title string
+	Body  strings.Builder
+}
+
+type Counter struct {
+	Count int
+	Lock  sync.Mutex
+}
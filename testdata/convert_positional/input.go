@@ -0,0 +1,19 @@
+package convert_positional
+
+type Person struct {
+	Name  string
+	Age   int
+	Email string
+}
+
+func main() {
+	_ = Person{"alice"}
+	_ = &Person{
+		"bob", // nickname
+		42,
+	}
+	// Complete literals are left unchanged
+	_ = Person{"carol", 30, "carol@example.com"}
+	// Mixing keyed and positional elements is not valid Go and is skipped
+	_ = Person{Name: "dave", 40}
+}
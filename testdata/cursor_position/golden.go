@@ -0,0 +1,18 @@
+package cursor_position
+
+type Person struct {
+	Name string
+	Age  int
+}
+
+type Team struct {
+	Lead Person
+	Size int
+}
+
+func main() {
+	_ = Person{Name: "first"}
+	_ = Team{
+		Lead: Person{Name: "lead", Age: 0},
+	}
+}
@@ -0,0 +1,37 @@
+package deep_nested
+
+type A struct {
+	Name string
+	B    B
+}
+
+type B struct {
+	Count int
+	C     C
+}
+
+type C struct {
+	Enabled bool
+	D       *D
+}
+
+type D struct {
+	ID    string
+	Value int
+}
+
+func main() {
+	_ = A{
+		Name: "",
+		B: B{
+			Count: 0,
+			C: C{
+				Enabled: false,
+				D: &D{
+					ID:    "id",
+					Value: 0,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,33 @@
+package deep_nested
+
+type A struct {
+	Name string
+	B    B
+}
+
+type B struct {
+	Count int
+	C     C
+}
+
+type C struct {
+	Enabled bool
+	D       *D
+}
+
+type D struct {
+	ID    string
+	Value int
+}
+
+func main() {
+	_ = A{
+		B: B{
+			C: C{
+				D: &D{
+					ID: "id",
+				},
+			},
+		},
+	}
+}
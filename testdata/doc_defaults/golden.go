@@ -0,0 +1,29 @@
+package doc_defaults
+
+// Client calls the remote service.
+//
+// Default: Timeout=30, Retries=3, Name="client", Unknown=1, Broken=(, =5
+type Client struct {
+	Name    string
+	Timeout int
+	Retries int
+	Broken  int
+	Debug   bool
+}
+
+type (
+	// Server serves requests.
+	// Default: Port=8080
+	Server struct {
+		Port int
+		Host string
+	}
+)
+
+func main() {
+	_ = Client{Name: "client", Timeout: 30, Retries: 5, Broken: 0, Debug: false}
+	_ = Server{
+		Port: 8080,
+		Host: "",
+	}
+}
@@ -0,0 +1,26 @@
+package doc_defaults
+
+// Client calls the remote service.
+//
+// Default: Timeout=30, Retries=3, Name="client", Unknown=1, Broken=(, =5
+type Client struct {
+	Name    string
+	Timeout int
+	Retries int
+	Broken  int
+	Debug   bool
+}
+
+type (
+	// Server serves requests.
+	// Default: Port=8080
+	Server struct {
+		Port int
+		Host string
+	}
+)
+
+func main() {
+	_ = Client{Retries: 5}
+	_ = Server{}
+}
@@ -0,0 +1,29 @@
+package embedded_ambiguous
+
+type Audit struct {
+	ID      string
+	Created int64
+}
+
+type Owner struct {
+	ID   string
+	Name string
+}
+
+// Document promotes ID from both Audit and Owner, so ID is ambiguous
+type Document struct {
+	Audit
+	*Owner
+	Title string
+}
+
+func main() {
+	_ = Document{
+		Audit: Audit{
+			ID:      "",
+			Created: 0,
+		},
+		Owner: nil,
+		Title: "draft",
+	}
+}
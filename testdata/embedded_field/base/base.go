@@ -0,0 +1,10 @@
+package base
+
+type Model struct {
+	ID      int
+	Version int
+}
+
+type Meta[T any] struct {
+	Value T
+}
@@ -0,0 +1,28 @@
+package embedded_field
+
+import "github.com/nametake/fillstruct/testdata/embedded_field/base"
+
+type timestamps struct {
+	Created int64
+	Updated int64
+}
+
+type User struct {
+	base.Model
+	*timestamps
+	base.Meta[string]
+	Name string
+}
+
+func main() {
+	_ = User{
+		Model: base.Model{
+			ID:      0,
+			Version: 0,
+		},
+		Meta: base.Meta[string]{
+			Value: "",
+		},
+		Name: "x",
+	}
+}
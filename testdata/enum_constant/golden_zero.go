@@ -0,0 +1,63 @@
+package enum_constant
+
+import "github.com/nametake/fillstruct/testdata/enum_constant/otherpkg"
+
+type Status int
+
+const (
+	StatusUnknown Status = iota
+	StatusActive
+	StatusInactive
+)
+
+type Level string
+
+const (
+	LevelDefault Level = ""
+	LevelDebug   Level = "debug"
+)
+
+// Priority has no constant with the zero value
+type Priority int
+
+const (
+	PriorityLow Priority = iota + 1
+	PriorityHigh
+)
+
+// Mode has more than one constant with the zero value
+type Mode int
+
+const (
+	ModeDefault Mode = 0
+	ModeNone    Mode = 0
+)
+
+type Task struct {
+	Name     string
+	Status   Status
+	Level    Level
+	Priority Priority
+	Mode     Mode
+	Kind     otherpkg.Kind
+	Entry    otherpkg.Entry
+}
+
+func main() {
+	_ = &Task{
+		Name:     "task",
+		Status:   0,
+		Level:    "",
+		Priority: 0,
+		Mode:     0,
+		Kind:     0,
+		Entry: otherpkg.Entry{
+			Name:       "",
+			Visibility: 0,
+		},
+	}
+	_ = &otherpkg.Entry{
+		Name:       "entry",
+		Visibility: 0,
+	}
+}
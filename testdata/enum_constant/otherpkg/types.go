@@ -0,0 +1,21 @@
+package otherpkg
+
+type Kind int
+
+const (
+	KindNone Kind = iota
+	KindFile
+	KindDir
+)
+
+type visibility int
+
+const (
+	visibilityDefault visibility = iota
+	visibilityHidden
+)
+
+type Entry struct {
+	Name       string
+	Visibility visibility
+}
@@ -0,0 +1,26 @@
+package example_func
+
+import "fmt"
+
+type Config struct {
+	Name    string
+	Port    int
+	Verbose bool
+}
+
+func ExampleConfig() {
+	c := Config{
+		Name:    "api",
+		Port:    0,
+		Verbose: false,
+	}
+	fmt.Println(c.Name, c.Port)
+	// Output:
+	// api 0
+}
+
+func ExampleConfig_unordered() {
+	fmt.Println(Config{Name: "", Port: 80, Verbose: false}.Port)
+	// Unordered output:
+	// 80
+}
@@ -0,0 +1,24 @@
+package example_func
+
+import "fmt"
+
+type Config struct {
+	Name    string
+	Port    int
+	Verbose bool
+}
+
+func ExampleConfig() {
+	c := Config{
+		Name: "api",
+	}
+	fmt.Println(c.Name, c.Port)
+	// Output:
+	// api 0
+}
+
+func ExampleConfig_unordered() {
+	fmt.Println(Config{Port: 80}.Port)
+	// Unordered output:
+	// 80
+}
@@ -0,0 +1,26 @@
+package exclude_field_regexp
+
+type Request struct {
+	Name                 string
+	XXX_NoUnkeyedLiteral struct{}
+	XXX_sizecache        int32
+	XXX_Unrecognized     []byte
+}
+
+type Response struct {
+	Code             int
+	Message          string
+	XXX_Unrecognized []byte
+}
+
+func main() {
+	_ = &Request{}
+	_ = &Response{
+		Code: 200,
+	}
+	// Excluded fields already set are kept
+	_ = &Response{
+		XXX_Unrecognized: nil,
+		Code:             404,
+	}
+}
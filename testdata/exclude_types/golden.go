@@ -0,0 +1,13 @@
+package exclude_types
+
+import "github.com/nametake/fillstruct/testdata/wildcard_types/models"
+
+func main() {
+	_ = models.User{
+		ID:   1,
+		Name: "",
+	}
+	_ = models.Order{
+		ID: 2,
+	}
+}
@@ -0,0 +1,12 @@
+package exclude_types
+
+import "github.com/nametake/fillstruct/testdata/wildcard_types/models"
+
+func main() {
+	_ = models.User{
+		ID: 1,
+	}
+	_ = models.Order{
+		ID: 2,
+	}
+}
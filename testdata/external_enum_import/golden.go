@@ -0,0 +1,13 @@
+package external_enum_import
+
+import (
+	"github.com/nametake/fillstruct/testdata/external_enum/otherpkg"
+	"github.com/nametake/fillstruct/testdata/external_enum_import/models"
+)
+
+func main() {
+	_ = &models.Config{
+		Name:   "test",
+		Status: otherpkg.StatusUnknown,
+	}
+}
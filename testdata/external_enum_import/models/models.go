@@ -0,0 +1,8 @@
+package models
+
+import "github.com/nametake/fillstruct/testdata/external_enum/otherpkg"
+
+type Config struct {
+	Name   string
+	Status otherpkg.Status
+}
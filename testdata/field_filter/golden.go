@@ -0,0 +1,23 @@
+package field_filter
+
+import "time"
+
+type Event struct {
+	Name      string
+	StartsAt  time.Time
+	Duration  time.Duration
+	CreatedAt time.Time
+}
+
+func main() {
+	_ = &Event{
+		Name:     "launch",
+		Duration: 0,
+	}
+	// Fields already set are kept even when the filter rejects them
+	_ = &Event{
+		Name:      "release",
+		Duration:  0,
+		CreatedAt: time.Now(),
+	}
+}
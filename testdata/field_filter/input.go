@@ -0,0 +1,21 @@
+package field_filter
+
+import "time"
+
+type Event struct {
+	Name      string
+	StartsAt  time.Time
+	Duration  time.Duration
+	CreatedAt time.Time
+}
+
+func main() {
+	_ = &Event{
+		Name: "launch",
+	}
+	// Fields already set are kept even when the filter rejects them
+	_ = &Event{
+		CreatedAt: time.Now(),
+		Name:      "release",
+	}
+}
@@ -0,0 +1,17 @@
+package field_patterns
+
+type Order struct {
+	OrderID    int
+	Name       string
+	UserID     int
+	Total      float64
+	InternalID string
+}
+
+func main() {
+	_ = Order{
+		OrderID: 0,
+		Name:    "order",
+		UserID:  0,
+	}
+}
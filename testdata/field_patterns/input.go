@@ -0,0 +1,15 @@
+package field_patterns
+
+type Order struct {
+	OrderID    int
+	Name       string
+	UserID     int
+	Total      float64
+	InternalID string
+}
+
+func main() {
+	_ = Order{
+		Name: "order",
+	}
+}
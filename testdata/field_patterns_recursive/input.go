@@ -0,0 +1,18 @@
+package field_patterns_recursive
+
+type Audit struct {
+	CreatedBy    string
+	InternalNote string
+}
+
+type Order struct {
+	Name       string
+	Audit      Audit
+	InternalID string
+}
+
+func main() {
+	_ = Order{
+		Name: "order",
+	}
+}
@@ -0,0 +1,17 @@
+package float_default
+
+type Measurement struct {
+	Name   string
+	Value  float64
+	Weight float32
+	Count  int
+}
+
+func main() {
+	_ = &Measurement{
+		Name:   "temperature",
+		Value:  1.5,
+		Weight: 0.5,
+		Count:  0,
+	}
+}
@@ -0,0 +1,14 @@
+package float_default
+
+type Measurement struct {
+	Name   string
+	Value  float64
+	Weight float32
+	Count  int
+}
+
+func main() {
+	_ = &Measurement{
+		Name: "temperature",
+	}
+}
@@ -0,0 +1,17 @@
+package float_fields
+
+type Measurement struct {
+	Name   string
+	Value  float64
+	Weight float32
+	Count  int
+}
+
+func main() {
+	_ = &Measurement{
+		Name:   "temperature",
+		Value:  0,
+		Weight: 0,
+		Count:  0,
+	}
+}
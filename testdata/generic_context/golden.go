@@ -0,0 +1,35 @@
+package generic_context
+
+type Config struct {
+	Name string
+	Port int
+}
+
+func identity[T any](v T) T {
+	return v
+}
+
+func first[T any](vs ...T) T {
+	return vs[0]
+}
+
+type Box[T any] struct {
+	Value T
+	Label string
+}
+
+func wrap[T any](v T) Box[T] {
+	return Box[T]{Value: v, Label: ""}
+}
+
+func main() {
+	_ = identity(Config{Name: "a", Port: 0})
+	_ = first(Config{Name: "", Port: 1}, Config{Name: "b", Port: 0})
+	_ = identity[Config](Config{
+		Name: "",
+		Port: 0,
+	})
+	_ = wrap(Config{Name: "c", Port: 0})
+	var c Config = identity(Config{Name: "d", Port: 0})
+	_ = c
+}
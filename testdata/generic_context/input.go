@@ -0,0 +1,32 @@
+package generic_context
+
+type Config struct {
+	Name string
+	Port int
+}
+
+func identity[T any](v T) T {
+	return v
+}
+
+func first[T any](vs ...T) T {
+	return vs[0]
+}
+
+type Box[T any] struct {
+	Value T
+	Label string
+}
+
+func wrap[T any](v T) Box[T] {
+	return Box[T]{Value: v}
+}
+
+func main() {
+	_ = identity(Config{Name: "a"})
+	_ = first(Config{Port: 1}, Config{Name: "b"})
+	_ = identity[Config](Config{})
+	_ = wrap(Config{Name: "c"})
+	var c Config = identity(Config{Name: "d"})
+	_ = c
+}
@@ -0,0 +1,30 @@
+package generic_external
+
+import (
+	"github.com/nametake/fillstruct/testdata/generic_external/models"
+	"github.com/nametake/fillstruct/testdata/generic_external/otherpkg"
+)
+
+func main() {
+	_ = &otherpkg.Envelope{
+		ID: "envelope",
+		User: otherpkg.Response[models.User]{
+			Data: models.User{
+				Name: "",
+			},
+			Error: "",
+		},
+		Users: otherpkg.Response[[]*models.User]{
+			Data:  nil,
+			Error: "",
+		},
+		ByName: otherpkg.Response[map[string]models.User]{
+			Data:  nil,
+			Error: "",
+		},
+		Message: otherpkg.Response[string]{
+			Data:  "",
+			Error: "",
+		},
+	}
+}
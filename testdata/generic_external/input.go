@@ -0,0 +1,9 @@
+package generic_external
+
+import "github.com/nametake/fillstruct/testdata/generic_external/otherpkg"
+
+func main() {
+	_ = &otherpkg.Envelope{
+		ID: "envelope",
+	}
+}
@@ -0,0 +1,16 @@
+package otherpkg
+
+import "github.com/nametake/fillstruct/testdata/generic_external/models"
+
+type Response[T any] struct {
+	Data  T
+	Error string
+}
+
+type Envelope struct {
+	ID      string
+	User    Response[models.User]
+	Users   Response[[]*models.User]
+	ByName  Response[map[string]models.User]
+	Message Response[string]
+}
@@ -0,0 +1,57 @@
+package generic_instantiated
+
+type Box[T any] struct {
+	Value T
+	Name  string
+}
+
+type Node[T any] struct {
+	Value    T
+	Next     *Node[T]
+	Children []Node[T]
+	Inner    Box[Box[T]]
+}
+
+type Tree struct {
+	Root  *Box[string]
+	Nodes Node[int]
+}
+
+func main() {
+	_ = Box[int]{Value: 0, Name: "x"}
+	_ = &Box[string]{
+		Value: "",
+		Name:  "pointer",
+	}
+	_ = Box[*Box[int]]{
+		Value: nil,
+		Name:  "nested",
+	}
+	_ = Node[float64]{
+		Value:    1.5,
+		Next:     nil,
+		Children: nil,
+		Inner: Box[Box[float64]]{
+			Value: Box[float64]{
+				Value: 0,
+				Name:  "",
+			},
+			Name: "",
+		},
+	}
+	_ = Tree{
+		Root: nil,
+		Nodes: Node[int]{
+			Value:    0,
+			Next:     nil,
+			Children: nil,
+			Inner: Box[Box[int]]{
+				Value: Box[int]{
+					Value: 0,
+					Name:  "",
+				},
+				Name: "",
+			},
+		},
+	}
+}
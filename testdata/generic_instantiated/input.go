@@ -0,0 +1,32 @@
+package generic_instantiated
+
+type Box[T any] struct {
+	Value T
+	Name  string
+}
+
+type Node[T any] struct {
+	Value    T
+	Next     *Node[T]
+	Children []Node[T]
+	Inner    Box[Box[T]]
+}
+
+type Tree struct {
+	Root  *Box[string]
+	Nodes Node[int]
+}
+
+func main() {
+	_ = Box[int]{Name: "x"}
+	_ = &Box[string]{
+		Name: "pointer",
+	}
+	_ = Box[*Box[int]]{
+		Name: "nested",
+	}
+	_ = Node[float64]{
+		Value: 1.5,
+	}
+	_ = Tree{}
+}
@@ -0,0 +1,33 @@
+package generic_pair
+
+type Pair[A, B any] struct {
+	First  A
+	Second B
+}
+
+type Box[T any] struct {
+	Value T
+}
+
+type Holder struct {
+	Name string
+	Pair Pair[int, string]
+	Box  Box[bool]
+}
+
+func main() {
+	_ = &Holder{
+		Name: "holder",
+		Pair: Pair[int, string]{
+			First:  0,
+			Second: "",
+		},
+		Box: Box[bool]{
+			Value: false,
+		},
+	}
+	_ = Pair[int, string]{
+		First:  1,
+		Second: "",
+	}
+}
@@ -0,0 +1,25 @@
+package generic_pair
+
+type Pair[A, B any] struct {
+	First  A
+	Second B
+}
+
+type Box[T any] struct {
+	Value T
+}
+
+type Holder struct {
+	Name string
+	Pair Pair[int, string]
+	Box  Box[bool]
+}
+
+func main() {
+	_ = &Holder{
+		Name: "holder",
+	}
+	_ = Pair[int, string]{
+		First: 1,
+	}
+}
@@ -0,0 +1,33 @@
+package grouped_var
+
+type A struct {
+	Name  string
+	Count int
+}
+
+type B struct {
+	Enabled bool
+	Label   string
+}
+
+var (
+	// a is the first value
+	a = A{
+		Name:  "a",
+		Count: 0,
+	}
+
+	b = B{
+		Enabled: false,
+		Label:   "b",
+	} // trailing comment on b
+
+	// c and d are declared together
+	c, d = A{
+		Name:  "",
+		Count: 1,
+	}, B{
+		Enabled: false,
+		Label:   "",
+	}
+)
@@ -0,0 +1,27 @@
+package grouped_var
+
+type A struct {
+	Name  string
+	Count int
+}
+
+type B struct {
+	Enabled bool
+	Label   string
+}
+
+var (
+	// a is the first value
+	a = A{
+		Name: "a",
+	}
+
+	b = B{
+		Label: "b",
+	} // trailing comment on b
+
+	// c and d are declared together
+	c, d = A{
+		Count: 1,
+	}, B{}
+)
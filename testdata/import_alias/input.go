@@ -0,0 +1,16 @@
+package import_alias
+
+import (
+	stdtime "time"
+
+	"github.com/nametake/fillstruct/testdata/auto_import/models"
+	_ "github.com/nametake/fillstruct/testdata/auto_import/owner"
+)
+
+var epoch = stdtime.Unix(0, 0)
+
+func main() {
+	_ = &models.Event{
+		Name: "launch",
+	}
+}
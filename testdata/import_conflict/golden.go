@@ -0,0 +1,20 @@
+package import_conflict
+
+import (
+	"github.com/nametake/fillstruct/testdata/auto_import/models"
+	"github.com/nametake/fillstruct/testdata/auto_import/owner"
+	"github.com/nametake/fillstruct/testdata/import_conflict/time"
+	time2 "time"
+)
+
+var clock = time.Clock{Zone: "UTC"}
+
+func main() {
+	_ = &models.Event{
+		Name: "launch",
+		When: time2.Time{},
+		Owner: owner.User{
+			Name: "",
+		},
+	}
+}
@@ -0,0 +1,14 @@
+package import_conflict
+
+import (
+	"github.com/nametake/fillstruct/testdata/auto_import/models"
+	"github.com/nametake/fillstruct/testdata/import_conflict/time"
+)
+
+var clock = time.Clock{Zone: "UTC"}
+
+func main() {
+	_ = &models.Event{
+		Name: "launch",
+	}
+}
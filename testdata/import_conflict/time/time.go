@@ -0,0 +1,6 @@
+// Package time shadows the name of the standard library package
+package time
+
+type Clock struct {
+	Zone string
+}
@@ -0,0 +1,19 @@
+package include_anonymous
+
+type Config struct {
+	Name string
+	Port int
+}
+
+type Other struct {
+	Value string
+}
+
+var config = struct {
+	Server Config
+	Debug  bool
+	Extra  Other
+}{
+	Server: Config{Name: "api"},
+	Extra:  Other{},
+}
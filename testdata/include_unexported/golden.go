@@ -0,0 +1,21 @@
+package include_unexported
+
+import "github.com/nametake/fillstruct/testdata/include_unexported/models"
+
+type cache struct {
+	Name  string
+	size  int
+	items map[string]string
+}
+
+func main() {
+	_ = cache{
+		Name:  "users",
+		size:  0,
+		items: nil,
+	}
+	// Unexported fields of other packages cannot be set
+	_ = models.Account{
+		ID: 1,
+	}
+}
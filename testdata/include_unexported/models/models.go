@@ -0,0 +1,6 @@
+package models
+
+type Account struct {
+	ID      int
+	balance int
+}
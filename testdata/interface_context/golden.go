@@ -0,0 +1,42 @@
+package interface_context
+
+type Config struct {
+	Name string
+	Port int
+}
+
+func (c Config) Get() Config { return c }
+
+type Getter interface {
+	Get() Config
+}
+
+type Factory interface {
+	New() Getter
+}
+
+type factory struct{}
+
+func (factory) New() Getter {
+	return Config{
+		Name: "returned as interface",
+		Port: 0,
+	}
+}
+
+var _ Getter = Config{
+	Name: "assigned to interface",
+	Port: 0,
+}
+
+var _ = []any{
+	Config{
+		Name: "element of interface slice",
+		Port: 0,
+	},
+}
+
+func main() {
+	var f Factory = factory{}
+	_ = f.New().Get()
+}
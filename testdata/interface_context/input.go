@@ -0,0 +1,39 @@
+package interface_context
+
+type Config struct {
+	Name string
+	Port int
+}
+
+func (c Config) Get() Config { return c }
+
+type Getter interface {
+	Get() Config
+}
+
+type Factory interface {
+	New() Getter
+}
+
+type factory struct{}
+
+func (factory) New() Getter {
+	return Config{
+		Name: "returned as interface",
+	}
+}
+
+var _ Getter = Config{
+	Name: "assigned to interface",
+}
+
+var _ = []any{
+	Config{
+		Name: "element of interface slice",
+	},
+}
+
+func main() {
+	var f Factory = factory{}
+	_ = f.New().Get()
+}
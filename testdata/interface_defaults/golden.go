@@ -0,0 +1,19 @@
+package interface_defaults
+
+import (
+	stdio "io"
+)
+
+type Logger struct {
+	Output stdio.Writer
+	Input  stdio.Reader
+	Err    error
+}
+
+func main() {
+	_ = Logger{
+		Output: stdio.Discard,
+		Input:  nil,
+		Err:    nil,
+	}
+}
@@ -0,0 +1,15 @@
+package interface_defaults
+
+import (
+	stdio "io"
+)
+
+type Logger struct {
+	Output stdio.Writer
+	Input  stdio.Reader
+	Err    error
+}
+
+func main() {
+	_ = Logger{}
+}
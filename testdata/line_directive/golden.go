@@ -0,0 +1,14 @@
+//line person.tmpl:1
+package line_directive
+
+type Person struct {
+	Name string
+	Age  int
+}
+
+func main() {
+	_ = Person{
+		Name: "alice",
+		Age:  0,
+	}
+}
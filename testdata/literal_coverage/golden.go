@@ -0,0 +1,24 @@
+package literal_coverage
+
+type Person struct {
+	Name string
+	Age  int
+}
+
+type Team struct {
+	Name    string
+	Members []Person
+}
+
+func main() {
+	_ = Person{Name: "complete", Age: 30}
+	_ = Person{Name: "incomplete", Age: 0}
+	_ = Team{
+		Name: "complete",
+		Members: []Person{
+			{Name: "a", Age: 1},
+			{Name: "", Age: 2},
+		},
+	}
+	_ = Person{"positional", 40}
+}
@@ -0,0 +1,9 @@
+package event
+
+import "time"
+
+type Event struct {
+	Name  string
+	When  time.Time
+	Count int
+}
@@ -0,0 +1,27 @@
+package literal_edits
+
+import (
+	"github.com/nametake/fillstruct/testdata/literal_edits/event"
+	"time"
+)
+
+var  unrelated   =  1
+
+func events() []event.Event {
+	return []event.Event{
+		{Name: "a", When: time.Time{}, Count: 0},
+		{Name: "", When: time.Time{}, Count: 0},
+		{
+			Name: "b",
+			When: time.Time{},
+			// count of calls
+			Count: 2,
+		},
+		{
+			Name:  "",
+			When:  time.Time{},
+			Count: 0,
+		},
+		{"c"},
+	}
+}
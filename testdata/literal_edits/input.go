@@ -0,0 +1,20 @@
+package literal_edits
+
+import "github.com/nametake/fillstruct/testdata/literal_edits/event"
+
+var  unrelated   =  1
+
+func events() []event.Event {
+	return []event.Event{
+		{Name: "a"},
+		{},
+		{
+			Name: "b",
+			// count of calls
+			Count: 2,
+		},
+		{
+		},
+		{"c"},
+	}
+}
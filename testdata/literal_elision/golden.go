@@ -0,0 +1,39 @@
+package literal_elision
+
+type User struct {
+	Name string
+	Age  int
+}
+
+type Team struct {
+	Lead    User
+	Members []User
+	ByName  map[string]User
+}
+
+func main() {
+	_ = []User{
+		{Name: "elided", Age: 0},
+		User{Name: "explicit", Age: 0},
+	}
+	_ = map[string]*User{
+		"elided":   {Name: "a", Age: 0},
+		"explicit": &User{Name: "b", Age: 0},
+	}
+	_ = [2]User{{
+		Name: "",
+		Age:  0,
+	}, User{
+		Name: "",
+		Age:  0,
+	}}
+	_ = Team{
+		Lead: User{Name: "lead", Age: 0},
+		Members: []User{
+			{Name: "", Age: 1},
+		},
+		ByName: map[string]User{
+			"x": {Name: "", Age: 2},
+		},
+	}
+}
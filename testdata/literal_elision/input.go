@@ -0,0 +1,33 @@
+package literal_elision
+
+type User struct {
+	Name string
+	Age  int
+}
+
+type Team struct {
+	Lead    User
+	Members []User
+	ByName  map[string]User
+}
+
+func main() {
+	_ = []User{
+		{Name: "elided"},
+		User{Name: "explicit"},
+	}
+	_ = map[string]*User{
+		"elided":   {Name: "a"},
+		"explicit": &User{Name: "b"},
+	}
+	_ = [2]User{{}, User{}}
+	_ = Team{
+		Lead: User{Name: "lead"},
+		Members: []User{
+			{Age: 1},
+		},
+		ByName: map[string]User{
+			"x": {Age: 2},
+		},
+	}
+}
@@ -0,0 +1,25 @@
+package map_struct_literals
+
+type Key struct {
+	Region string
+	ID     int
+}
+
+type Config struct {
+	Name    string
+	Retries int
+	Enabled bool
+}
+
+func main() {
+	_ = map[string]Config{
+		"a": {Name: "x", Retries: 0, Enabled: false},
+		"b": Config{Name: "", Retries: 3, Enabled: false},
+	}
+	_ = map[Key]string{
+		{Region: "eu", ID: 0}: "europe",
+	}
+	_ = map[Key]Config{
+		{Region: "", ID: 1}: {Name: "", Retries: 0, Enabled: true},
+	}
+}
@@ -0,0 +1,25 @@
+package map_struct_literals
+
+type Key struct {
+	Region string
+	ID     int
+}
+
+type Config struct {
+	Name    string
+	Retries int
+	Enabled bool
+}
+
+func main() {
+	_ = map[string]Config{
+		"a": {Name: "x"},
+		"b": Config{Retries: 3},
+	}
+	_ = map[Key]string{
+		{Region: "eu"}: "europe",
+	}
+	_ = map[Key]Config{
+		{ID: 1}: {Enabled: true},
+	}
+}
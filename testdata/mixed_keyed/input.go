@@ -0,0 +1,14 @@
+package mixed_keyed
+
+type Config struct {
+	Port int
+	Name string
+	Tags []string
+}
+
+func main() {
+	// Positional literals are valid but only keyed literals are filled
+	_ = Config{8080, "x", nil}
+	// Mixing keyed and positional elements does not compile and is skipped as well
+	_ = Config{1, Name: "x"}
+}
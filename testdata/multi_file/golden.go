@@ -0,0 +1,9 @@
+package multi_file
+
+func (h *Handler) Clone() *Handler {
+	return &Handler{
+		Name:   h.Name,
+		Status: StatusUnknown,
+		Routes: nil,
+	}
+}
@@ -0,0 +1,7 @@
+package multi_file
+
+func (h *Handler) Clone() *Handler {
+	return &Handler{
+		Name: h.Name,
+	}
+}
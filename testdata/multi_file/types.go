@@ -0,0 +1,16 @@
+package multi_file
+
+type Status int
+
+const (
+	StatusUnknown Status = iota
+	StatusActive
+)
+
+type Handler struct {
+	Name   string
+	Status Status
+	Routes []string
+}
+
+func (h *Handler) Serve() {}
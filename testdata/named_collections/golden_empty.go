@@ -0,0 +1,31 @@
+package named_collections
+
+type Tags []string
+
+type Headers map[string]string
+
+type Events chan string
+
+type HandlerFunc func(string) error
+
+type Matrix [2][2]int
+
+type Request struct {
+	Path    string
+	Tags    Tags
+	Headers Headers
+	Events  Events
+	Handler HandlerFunc
+	Matrix  Matrix
+}
+
+func main() {
+	_ = Request{
+		Path:    "/",
+		Tags:    Tags{},
+		Headers: Headers{},
+		Events:  nil,
+		Handler: nil,
+		Matrix:  Matrix{},
+	}
+}
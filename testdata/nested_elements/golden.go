@@ -0,0 +1,21 @@
+package nested_elements
+
+type User struct {
+	Name string
+	Age  int
+}
+
+func main() {
+	_ = [][]User{
+		{{Name: "a", Age: 0}, {Name: "", Age: 1}},
+		{},
+	}
+	_ = [...]*User{
+		{Name: "pointer", Age: 0},
+	}
+	_ = []struct {
+		Users []User
+	}{
+		{Users: []User{{Name: "in anonymous", Age: 0}}},
+	}
+}
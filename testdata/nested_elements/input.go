@@ -0,0 +1,21 @@
+package nested_elements
+
+type User struct {
+	Name string
+	Age  int
+}
+
+func main() {
+	_ = [][]User{
+		{{Name: "a"}, {Age: 1}},
+		{},
+	}
+	_ = [...]*User{
+		{Name: "pointer"},
+	}
+	_ = []struct {
+		Users []User
+	}{
+		{Users: []User{{Name: "in anonymous"}}},
+	}
+}
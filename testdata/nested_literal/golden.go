@@ -0,0 +1,27 @@
+package nested_literal
+
+type Address struct {
+	City string
+	Zip  string
+}
+
+type Person struct {
+	Name    string
+	Home    Address
+	Work    *Address
+	Country string
+}
+
+func main() {
+	_ = Person{
+		Name: "alice",
+		Home: Address{
+			City: "",
+			Zip:  "",
+		},
+		Work: &Address{
+			City: "",
+			Zip:  "",
+		},
+	}
+}
@@ -11,7 +11,9 @@ type Person struct {
 
 func main() {
 	_ = &Person{
-		Name:    "",
-		Address: Address{},
+		Name: "",
+		Address: Address{
+			City: "",
+		},
 	}
 }
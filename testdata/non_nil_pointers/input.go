@@ -0,0 +1,27 @@
+package non_nil_pointers
+
+import "github.com/nametake/fillstruct/testdata/auto_import/owner"
+
+type Address struct {
+	City string
+}
+
+type Node struct {
+	Value int
+	Next  *Node
+}
+
+type Person struct {
+	Name     string
+	Home     *Address
+	Owner    *owner.User
+	Nickname *string
+	Tree     *Node
+	Other    *struct{ A int }
+}
+
+func main() {
+	_ = Person{
+		Name: "alice",
+	}
+}
@@ -0,0 +1,22 @@
+package non_struct_literals
+
+type IDs []int
+
+type Key struct {
+	Region string
+	ID     int
+}
+
+func main() {
+	_ = []int{1, 2, 3}
+	_ = [3]string{}
+	_ = [...]bool{true}
+	_ = []int{}
+	_ = IDs{1}
+	_ = [][]float64{{1.5}, {}}
+	_ = map[string]int{"a": 1}
+	_ = map[Key]int{
+		{Region: "", ID: 1}: 2,
+	}
+	_ = map[string][]string{"a": {"b"}}
+}
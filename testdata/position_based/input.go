@@ -7,4 +7,5 @@ type Person struct {
 
 func main() {
 	_ = &Person{"", 0}
+	_ = Person{"bob"}
 }
@@ -0,0 +1,18 @@
+package present_zero_value
+
+type Config struct {
+	Name    string
+	Count   int
+	Enabled bool
+	Tags    []string
+}
+
+func main() {
+	_ = &Config{
+		Name: "",
+		// Count is intentionally zero
+		Count:   0, // keep
+		Enabled: false,
+		Tags:    nil,
+	}
+}
@@ -0,0 +1,65 @@
+package recursive
+
+import "time"
+
+type Config struct {
+	Name     string
+	Server   Server
+	Database *Database
+	Cache    Pair[Limits]
+	Head     Node
+}
+
+type Server struct {
+	Host    string
+	Port    int
+	Limits  Limits
+	Started time.Time
+}
+
+type Limits struct {
+	MaxConns int
+	internal bool
+}
+
+type Database struct {
+	DSN string
+}
+
+type Pair[T any] struct {
+	First  T
+	Second T
+}
+
+// Node refers to itself through a pointer, which is filled with nil
+type Node struct {
+	Value int
+	Next  *Node
+}
+
+func main() {
+	_ = Config{
+		Name: "app",
+		Server: Server{
+			Host: "",
+			Port: 0,
+			Limits: Limits{
+				MaxConns: 0,
+			},
+			Started: time.Time{},
+		},
+		Database: nil,
+		Cache: Pair[Limits]{
+			First: Limits{
+				MaxConns: 0,
+			},
+			Second: Limits{
+				MaxConns: 0,
+			},
+		},
+		Head: Node{
+			Value: 0,
+			Next:  nil,
+		},
+	}
+}
@@ -0,0 +1,25 @@
+package recursive_defaults
+
+const DefaultRetries = 3
+
+type Service struct {
+	Name   string
+	Client Client
+	Server Server
+}
+
+type Client struct {
+	Retries int `fillstruct:"default=DefaultRetries"`
+	Debug   bool
+}
+
+// Server serves requests.
+// Default: Port=8080
+type Server struct {
+	Port int
+	Host string
+}
+
+func main() {
+	_ = Service{Name: "api"}
+}
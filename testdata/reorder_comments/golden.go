@@ -0,0 +1,20 @@
+package reorder_comments
+
+type Server struct {
+	Host    string
+	Port    int
+	Timeout int
+	Debug   bool
+	Name    string
+}
+
+func main() {
+	_ = Server{
+		Host: "",
+		// Port is fixed in production
+		Port:    8080,
+		Timeout: 0,
+		Debug:   true,  /* only locally */
+		Name:    "api", // the name
+	}
+}
@@ -0,0 +1,18 @@
+package reorder_comments
+
+type Server struct {
+	Host    string
+	Port    int
+	Timeout int
+	Debug   bool
+	Name    string
+}
+
+func main() {
+	_ = Server{
+		Name: "api", // the name
+		// Port is fixed in production
+		Port: 8080,
+		Debug: true, /* only locally */
+	}
+}
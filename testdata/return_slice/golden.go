@@ -0,0 +1,32 @@
+package return_slice
+
+type User struct {
+	Name  string
+	Email string
+	Admin bool
+}
+
+func users() []User {
+	return []User{
+		{
+			Name:  "alice",
+			Email: "",
+			Admin: false,
+		},
+		{
+			Name:  "",
+			Email: "bob@example.com",
+			Admin: false,
+		},
+	}
+}
+
+func pointers() []*User {
+	return []*User{
+		{
+			Name:  "carol",
+			Email: "",
+			Admin: false,
+		},
+	}
+}
@@ -0,0 +1,26 @@
+package return_slice
+
+type User struct {
+	Name  string
+	Email string
+	Admin bool
+}
+
+func users() []User {
+	return []User{
+		{
+			Name: "alice",
+		},
+		{
+			Email: "bob@example.com",
+		},
+	}
+}
+
+func pointers() []*User {
+	return []*User{
+		{
+			Name: "carol",
+		},
+	}
+}
@@ -0,0 +1,9 @@
+package config
+
+type Thing struct {
+	Path string
+}
+
+func Default() Thing {
+	return Thing{Path: "/etc/app"}
+}
@@ -0,0 +1,5 @@
+package config
+
+type Thing struct {
+	URL string
+}
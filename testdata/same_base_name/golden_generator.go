@@ -0,0 +1,19 @@
+package same_base_name
+
+import (
+	config2 "github.com/nametake/fillstruct/testdata/same_base_name/alpha/config"
+	"github.com/nametake/fillstruct/testdata/same_base_name/beta/config"
+	"github.com/nametake/fillstruct/testdata/same_base_name/models"
+)
+
+var fallback = config.Thing{URL: "https://example.com"}
+
+func main() {
+	_ = &models.Settings{
+		Name:    "default",
+		Primary: config2.Default(),
+		Fallback: config.Thing{
+			URL: "",
+		},
+	}
+}
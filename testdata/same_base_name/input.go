@@ -0,0 +1,14 @@
+package same_base_name
+
+import (
+	"github.com/nametake/fillstruct/testdata/same_base_name/beta/config"
+	"github.com/nametake/fillstruct/testdata/same_base_name/models"
+)
+
+var fallback = config.Thing{URL: "https://example.com"}
+
+func main() {
+	_ = &models.Settings{
+		Name: "default",
+	}
+}
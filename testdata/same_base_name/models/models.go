@@ -0,0 +1,12 @@
+package models
+
+import (
+	"github.com/nametake/fillstruct/testdata/same_base_name/alpha/config"
+	betaconfig "github.com/nametake/fillstruct/testdata/same_base_name/beta/config"
+)
+
+type Settings struct {
+	Name     string
+	Primary  config.Thing
+	Fallback betaconfig.Thing
+}
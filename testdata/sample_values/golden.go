@@ -0,0 +1,22 @@
+package sample_values
+
+import (
+	"github.com/nametake/fillstruct/testdata/sample_values/models"
+	"time"
+)
+
+func main() {
+	_ = models.User{
+		ID:        1,
+		Name:      "alice",
+		Email:     "user@example.com",
+		Website:   "https://example.com",
+		Age:       30,
+		Active:    true,
+		Status:    0,
+		Notes:     "",
+		CreatedAt: time.Now(),
+		Friends:   nil,
+		OwnerID:   "",
+	}
+}
@@ -0,0 +1,9 @@
+package sample_values
+
+import "github.com/nametake/fillstruct/testdata/sample_values/models"
+
+func main() {
+	_ = models.User{
+		Name: "alice",
+	}
+}
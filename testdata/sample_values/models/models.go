@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+type Status int
+
+type User struct {
+	ID        int64
+	Name      string
+	Email     string
+	Website   string
+	Age       int
+	Active    bool
+	Status    Status
+	Notes     string
+	CreatedAt time.Time
+	Friends   []int64
+	OwnerID   string
+}
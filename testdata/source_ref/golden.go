@@ -0,0 +1,26 @@
+package source_ref
+
+import "github.com/nametake/fillstruct/testdata/source_ref/models"
+
+type Order struct {
+	ID    int
+	Buyer models.User
+	Notes []string
+}
+
+func main() {
+	_ = Order{
+		ID: 1,
+		Buyer: models.User{
+			ID:    0,  // field defined at github.com/nametake/fillstruct/testdata/source_ref/models/models.go:4
+			Name:  "", // field defined at github.com/nametake/fillstruct/testdata/source_ref/models/models.go:5
+			Email: "", // field defined at github.com/nametake/fillstruct/testdata/source_ref/models/models.go:8
+		}, // field defined at input.go:7
+		Notes: nil, // field defined at input.go:8
+	}
+	_ = models.User{
+		ID:    0, // field defined at github.com/nametake/fillstruct/testdata/source_ref/models/models.go:4
+		Name:  "alice",
+		Email: "", // field defined at github.com/nametake/fillstruct/testdata/source_ref/models/models.go:8
+	}
+}
@@ -0,0 +1,16 @@
+package source_ref
+
+import "github.com/nametake/fillstruct/testdata/source_ref/models"
+
+type Order struct {
+	ID    int
+	Buyer models.User
+	Notes []string
+}
+
+func main() {
+	_ = Order{
+		ID: 1,
+	}
+	_ = models.User{Name: "alice"}
+}
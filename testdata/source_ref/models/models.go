@@ -0,0 +1,9 @@
+package models
+
+type User struct {
+	ID   int
+	Name string
+
+	// Email is optional
+	Email string
+}
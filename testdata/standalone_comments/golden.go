@@ -0,0 +1,27 @@
+package standalone_comments
+
+type Server struct {
+	Name    string
+	Host    string
+	Port    int
+	Timeout int
+	Retries int
+	Debug   bool
+}
+
+func main() {
+	_ = Server{
+		// group 1: identity
+		Name: "api",
+		Host: "localhost",
+
+		// group 2: networking
+
+		Port:    8080,
+		Timeout: 0,
+		Retries: 0,
+		Debug:   false,
+
+		// trailing notes stay at the end
+	}
+}
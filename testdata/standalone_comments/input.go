@@ -0,0 +1,24 @@
+package standalone_comments
+
+type Server struct {
+	Name    string
+	Host    string
+	Port    int
+	Timeout int
+	Retries int
+	Debug   bool
+}
+
+func main() {
+	_ = Server{
+		// group 2: networking
+
+		Port: 8080,
+		Host: "localhost",
+
+		// group 1: identity
+		Name: "api",
+
+		// trailing notes stay at the end
+	}
+}
@@ -0,0 +1,17 @@
+package struct_tags
+
+type Record struct {
+	ID       int    `json:"id" db:"id"`
+	Name     string `json:"name,omitempty"`
+	Email    string `db:"email" validate:"required,email"`
+	Note     string `this is not a conventional tag`
+	Raw      string `json:"raw`
+	Empty    string ``
+	Untagged bool
+}
+
+func main() {
+	_ = &Record{
+		ID: 1,
+	}
+}
@@ -0,0 +1,23 @@
+package stub_funcs
+
+import "context"
+
+type HandlerFunc func(ctx context.Context, name string) bool
+
+type Hooks struct {
+	Run     func(ctx context.Context) error
+	OnClose func()
+	Convert func(string, ...int) (int, error)
+	Handle  HandlerFunc
+	Name    string
+}
+
+func main() {
+	_ = Hooks{
+		Run:     func(ctx context.Context) error { return nil },
+		OnClose: func() {},
+		Convert: func(string, ...int) (int, error) { return 0, nil },
+		Handle:  func(ctx context.Context, name string) bool { return false },
+		Name:    "hooks",
+	}
+}
@@ -0,0 +1,19 @@
+package stub_funcs
+
+import "context"
+
+type HandlerFunc func(ctx context.Context, name string) bool
+
+type Hooks struct {
+	Run     func(ctx context.Context) error
+	OnClose func()
+	Convert func(string, ...int) (int, error)
+	Handle  HandlerFunc
+	Name    string
+}
+
+func main() {
+	_ = Hooks{
+		Name: "hooks",
+	}
+}
@@ -0,0 +1,25 @@
+package tag_default
+
+import (
+	"time"
+
+	"github.com/nametake/fillstruct/testdata/tag_default/otherpkg"
+)
+
+var DefaultTimeout = 30 * time.Second
+
+const DefaultName = "server"
+
+type Server struct {
+	Name    string        `json:"name" fillstruct:"default=DefaultName"`
+	Timeout time.Duration `fillstruct:"default=DefaultTimeout"`
+	Port    int           `fillstruct:"default=8080"`
+	Host    string        `fillstruct:"default=Missing"`
+	Limit   int           `fillstruct:"default=DefaultTimeout"`
+	Client  otherpkg.Client
+}
+
+func main() {
+	_ = Server{}
+	_ = otherpkg.Client{}
+}
@@ -0,0 +1,11 @@
+package otherpkg
+
+// DefaultRetries is the number of retries used when none is configured.
+var DefaultRetries = 3
+
+const defaultBackoff = 2
+
+type Client struct {
+	Retries int `fillstruct:"default=DefaultRetries"`
+	Backoff int `fillstruct:"default=defaultBackoff"`
+}
@@ -0,0 +1,24 @@
+package tag_skip
+
+type Credentials struct {
+	User     string
+	Secret   string `fillstruct:"-"`
+	Token    string `json:"token" fill:"-"`
+	Computed int    `fillstruct:"-" json:"computed"`
+	Retries  int    `fillstruct:"default=3"`
+}
+
+func main() {
+	_ = Credentials{
+		User:    "admin",
+		Token:   "",
+		Retries: 3,
+	}
+	// Skipped fields already set are kept
+	_ = Credentials{
+		User:    "root",
+		Secret:  "s3cr3t",
+		Token:   "",
+		Retries: 3,
+	}
+}
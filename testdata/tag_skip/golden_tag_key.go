@@ -0,0 +1,25 @@
+package tag_skip
+
+type Credentials struct {
+	User     string
+	Secret   string `fillstruct:"-"`
+	Token    string `json:"token" fill:"-"`
+	Computed int    `fillstruct:"-" json:"computed"`
+	Retries  int    `fillstruct:"default=3"`
+}
+
+func main() {
+	_ = Credentials{
+		User:     "admin",
+		Secret:   "",
+		Computed: 0,
+		Retries:  0,
+	}
+	// Skipped fields already set are kept
+	_ = Credentials{
+		User:     "root",
+		Secret:   "s3cr3t",
+		Computed: 0,
+		Retries:  0,
+	}
+}
@@ -0,0 +1,20 @@
+package tag_skip
+
+type Credentials struct {
+	User     string
+	Secret   string `fillstruct:"-"`
+	Token    string `json:"token" fill:"-"`
+	Computed int    `fillstruct:"-" json:"computed"`
+	Retries  int    `fillstruct:"default=3"`
+}
+
+func main() {
+	_ = Credentials{
+		User: "admin",
+	}
+	// Skipped fields already set are kept
+	_ = Credentials{
+		Secret: "s3cr3t",
+		User:   "root",
+	}
+}
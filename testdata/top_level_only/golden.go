@@ -0,0 +1,31 @@
+package top_level_only
+
+type Config struct {
+	Name string
+	Port int
+}
+
+var DefaultConfig = Config{
+	Name: "default",
+	Port: 0,
+}
+
+var (
+	configs = []Config{
+		{
+			Name: "a",
+			Port: 0,
+		},
+	}
+	newConfig = func() Config {
+		return Config{
+			Name: "func literal",
+		}
+	}
+)
+
+func main() {
+	_ = &Config{
+		Name: "main",
+	}
+}
@@ -0,0 +1,29 @@
+package top_level_only
+
+type Config struct {
+	Name string
+	Port int
+}
+
+var DefaultConfig = Config{
+	Name: "default",
+}
+
+var (
+	configs = []Config{
+		{
+			Name: "a",
+		},
+	}
+	newConfig = func() Config {
+		return Config{
+			Name: "func literal",
+		}
+	}
+)
+
+func main() {
+	_ = &Config{
+		Name: "main",
+	}
+}
@@ -0,0 +1,14 @@
+package type_generator
+
+import (
+	"github.com/nametake/fillstruct/testdata/type_generator/models"
+	"github.com/nametake/fillstruct/testdata/type_generator/money"
+)
+
+func main() {
+	_ = models.Invoice{
+		ID:    1,
+		Total: money.Zero(),
+		Tax:   nil,
+	}
+}
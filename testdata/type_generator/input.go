@@ -0,0 +1,9 @@
+package type_generator
+
+import "github.com/nametake/fillstruct/testdata/type_generator/models"
+
+func main() {
+	_ = models.Invoice{
+		ID: 1,
+	}
+}
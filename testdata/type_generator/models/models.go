@@ -0,0 +1,9 @@
+package models
+
+import "github.com/nametake/fillstruct/testdata/type_generator/money"
+
+type Invoice struct {
+	ID    int
+	Total money.Amount
+	Tax   *money.Amount
+}
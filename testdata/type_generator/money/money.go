@@ -0,0 +1,9 @@
+package money
+
+type Amount struct {
+	cents int64
+}
+
+func Zero() Amount {
+	return Amount{}
+}
@@ -0,0 +1,38 @@
+package typed_nil
+
+import (
+	"io"
+	"time"
+)
+
+type User struct {
+	Name string
+}
+
+type Handlers struct {
+	Owner    *User
+	Location *time.Location
+	Tags     []string
+	Counts   map[string]int
+	Events   chan<- int
+	Hook     func(int, ...string) error
+	Err      error
+	Reader   io.Reader
+	Any      interface{}
+	Closer   interface{ Close() error }
+}
+
+func main() {
+	_ = Handlers{
+		Owner:    (*User)(nil),
+		Location: (*time.Location)(nil),
+		Tags:     []string(nil),
+		Counts:   map[string]int(nil),
+		Events:   (chan<- int)(nil),
+		Hook:     (func(int, ...string) error)(nil),
+		Err:      error(nil),
+		Reader:   io.Reader(nil),
+		Any:      interface{}(nil),
+		Closer:   nil,
+	}
+}
@@ -0,0 +1,38 @@
+package typed_nil
+
+import (
+	"io"
+	"time"
+)
+
+type User struct {
+	Name string
+}
+
+type Handlers struct {
+	Owner    *User
+	Location *time.Location
+	Tags     []string
+	Counts   map[string]int
+	Events   chan<- int
+	Hook     func(int, ...string) error
+	Err      error
+	Reader   io.Reader
+	Any      interface{}
+	Closer   interface{ Close() error }
+}
+
+func main() {
+	_ = Handlers{
+		Owner:    nil,
+		Location: nil,
+		Tags:     nil,
+		Counts:   nil,
+		Events:   nil,
+		Hook:     nil,
+		Err:      nil,
+		Reader:   nil,
+		Any:      nil,
+		Closer:   nil,
+	}
+}
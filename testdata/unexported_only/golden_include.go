@@ -0,0 +1,10 @@
+package unexported_only
+
+type counter struct {
+	mu    int
+	count int
+}
+
+func main() {
+	_ = counter{mu: 0, count: 1}
+}
@@ -0,0 +1,13 @@
+package unknown_placeholder
+
+type Box[T any] struct {
+	Name  string
+	Value T
+}
+
+func NewBox[T any]() Box[T] {
+	return Box[T]{
+		Name:  "box",
+		Value: FILLSTRUCT_TODO,
+	}
+}
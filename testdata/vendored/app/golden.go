@@ -0,0 +1,11 @@
+package app
+
+import "example.com/lib"
+
+func main() {
+	_ = &lib.Config{
+		Name: "vendored",
+		Host: "",
+		Port: 0,
+	}
+}
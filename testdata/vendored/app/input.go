@@ -0,0 +1,9 @@
+package app
+
+import "example.com/lib"
+
+func main() {
+	_ = &lib.Config{
+		Name: "vendored",
+	}
+}
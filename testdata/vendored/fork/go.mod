@@ -0,0 +1,3 @@
+module example.com/fork/lib
+
+go 1.25
@@ -0,0 +1,7 @@
+package lib
+
+type Config struct {
+	Name string
+	Host string
+	Port int
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+type User struct {
+	ID   int
+	Name string
+}
+
+type Order struct {
+	ID    int
+	Items []Item
+}
+
+type Item struct {
+	SKU string
+}
+
+type Page[T any] struct {
+	Items []T
+}
+
+// Status is not a struct type
+type Status int
+
+// Timestamp denotes a type declared elsewhere
+type Timestamp = time.Time
@@ -0,0 +1,10 @@
+package app
+
+import "example.com/lib"
+
+func main() {
+	_ = &lib.Config{
+		Name: "app",
+		Port: 0,
+	}
+}
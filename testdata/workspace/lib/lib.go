@@ -0,0 +1,6 @@
+package lib
+
+type Config struct {
+	Name string
+	Port int
+}